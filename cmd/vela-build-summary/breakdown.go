@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Breakdown represents where the time for a build was spent,
+// from when the build was created until it finished.
+//
+// The durations add up to the total time for the build, so
+// time spent waiting is separated from time spent running.
+type Breakdown struct {
+	// duration from when the build was created until it was enqueued
+	Pending time.Duration
+	// duration the build waited in the queue for a worker
+	Queued time.Duration
+	// duration the build ran for without any steps running,
+	// such as between steps or while pulling images
+	Idle time.Duration
+	// duration the build ran for with at least one step running
+	Running time.Duration
+}
+
+// Total returns the total duration for the build from the breakdown.
+func (b *Breakdown) Total() time.Duration {
+	return b.Pending + b.Queued + b.Idle + b.Running
+}
+
+// String returns the breakdown formatted for display.
+func (b *Breakdown) String() string {
+	return fmt.Sprintf("%s pending, %s queued for a worker, %s between steps, %s running", b.Pending, b.Queued, b.Idle, b.Running)
+}
+
+// Wait returns the duration the resource waited before it started formatted for display.
+//
+// A "-" is returned for resources that never started running.
+func (r *Resource) Wait() string {
+	// check if the resource started running
+	if r.Started == 0 {
+		return "-"
+	}
+
+	return r.Queued().String()
+}
+
+// span is a helper function to calculate the duration
+// covered by the provided list of [start, end] intervals.
+func span(intervals [][2]int64) int64 {
+	// sort the intervals based off when they start
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i][0] < intervals[j][0]
+	})
+
+	// create variables to track the covered duration and the current interval
+	var covered, start, end int64
+
+	for i, interval := range intervals {
+		// check if the interval overlaps the current interval
+		if i > 0 && interval[0] <= end {
+			end = max(end, interval[1])
+
+			continue
+		}
+
+		covered += end - start
+		start, end = interval[0], interval[1]
+	}
+
+	return covered + end - start
+}
+
+// newBreakdown is a helper function to calculate where the time
+// for the build was spent from the timestamps for the build and
+// the steps in the build.
+//
+// Services run alongside the steps for the entire build, so only
+// the steps are used to determine when the build was running.
+func newBreakdown(s *Summary) *Breakdown {
+	logrus.Debug("calculating time breakdown for build summary")
+
+	b := new(Breakdown)
+	build := s.Totals
+
+	// check if the build was enqueued
+	if build.Enqueued > 0 && build.Created > 0 {
+		b.Pending = time.Duration(max(build.Enqueued-build.Created, 0)) * time.Second
+	}
+
+	// check if the build started running
+	if build.Started == 0 {
+		// the build is still waiting in the queue
+		if build.Enqueued > 0 || build.Created > 0 {
+			b.Queued = time.Duration(max(time.Now().Unix()-max(build.Enqueued, build.Created), 0)) * time.Second
+		}
+
+		return b
+	}
+
+	b.Queued = build.Queued()
+
+	// calculate when the build finished, or how long
+	// a build that is still running has run for so far
+	end := finish(build)
+
+	// create a list of the times each step was running within the build
+	intervals := [][2]int64{}
+
+	for _, r := range s.Steps {
+		// check if the step started running
+		if r.Started == 0 {
+			continue
+		}
+
+		start, stop := max(r.Started, build.Started), min(finish(r), end)
+
+		// check if the step ran within the build
+		if stop > start {
+			intervals = append(intervals, [2]int64{start, stop})
+		}
+	}
+
+	b.Running = time.Duration(span(intervals)) * time.Second
+	b.Idle = max(time.Duration(end-build.Started)*time.Second-b.Running, 0)
+
+	return b
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-vela/server/constants"
+)
+
+// testStepAt is a helper function to produce a step with the provided
+// name, status and duration that started at the provided unix timestamp.
+//
+// A step with no start never started running.
+func testStepAt(name, status string, started int64, duration time.Duration) *Resource {
+	r := testStep(name, status, duration)
+	r.Started = started
+
+	// check if the step completed
+	if r.Finished > 0 {
+		r.Finished = started + int64(duration.Seconds())
+	}
+
+	return r
+}
+
+// testBuild is a helper function to produce a build summary with the
+// provided steps for a build that was created at 100, enqueued at 102
+// and ran from 110 until the provided unix timestamp.
+//
+// A build with no finish is still running.
+func testBuild(finished int64, steps ...*Resource) *Summary {
+	s := testSummary(1, steps...)
+
+	s.Totals.Created = 100
+	s.Totals.Enqueued = 102
+	s.Totals.Started = 110
+	s.Totals.Finished = finished
+	s.Totals.Duration = time.Duration(finished-110) * time.Second
+
+	// check if the build is still running, so it has run for a minute so far
+	if finished == 0 {
+		s.Totals.Status = constants.StatusRunning
+		s.Totals.Duration = time.Minute
+	}
+
+	return s
+}
+
+func TestSpan(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name      string
+		intervals [][2]int64
+		want      int64
+	}{
+		{
+			name: "no intervals",
+		},
+		{
+			name:      "single interval",
+			intervals: [][2]int64{{10, 20}},
+			want:      10,
+		},
+		{
+			name:      "overlapping intervals",
+			intervals: [][2]int64{{10, 30}, {20, 40}},
+			want:      30,
+		},
+		{
+			name:      "contained interval",
+			intervals: [][2]int64{{10, 40}, {20, 30}},
+			want:      30,
+		},
+		{
+			name:      "adjacent intervals",
+			intervals: [][2]int64{{10, 20}, {20, 30}},
+			want:      20,
+		},
+		{
+			name:      "unsorted intervals with a gap",
+			intervals: [][2]int64{{50, 60}, {10, 20}, {15, 30}},
+			want:      30,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := span(test.intervals)
+
+			if got != test.want {
+				t.Errorf("span is %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewBreakdown(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		summary *Summary
+		running time.Duration
+		idle    time.Duration
+	}{
+		{
+			name: "sequential steps",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 10*time.Second),
+				testStepAt("test", constants.StatusSuccess, 120, 30*time.Second),
+			),
+			running: 40 * time.Second,
+			idle:    20 * time.Second,
+		},
+		{
+			name: "overlapping steps",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 30*time.Second),
+				testStepAt("test", constants.StatusSuccess, 120, 30*time.Second),
+			),
+			running: 40 * time.Second,
+			idle:    20 * time.Second,
+		},
+		{
+			name: "gap between steps",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 10*time.Second),
+				testStepAt("test", constants.StatusSuccess, 130, 20*time.Second),
+			),
+			running: 30 * time.Second,
+			idle:    30 * time.Second,
+		},
+		{
+			name: "steps outside the build",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 100, 20*time.Second),
+				testStepAt("test", constants.StatusSuccess, 160, 20*time.Second),
+			),
+			running: 20 * time.Second,
+			idle:    40 * time.Second,
+		},
+		{
+			name: "pending step",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 40*time.Second),
+				testStepAt("test", constants.StatusPending, 0, 0),
+			),
+			running: 40 * time.Second,
+			idle:    20 * time.Second,
+		},
+		{
+			name: "injected steps",
+			summary: testBuild(170,
+				testStepAt("init", constants.StatusSuccess, 110, 5*time.Second),
+				testStepAt("clone", constants.StatusSuccess, 115, 5*time.Second),
+			),
+			running: 10 * time.Second,
+			idle:    50 * time.Second,
+		},
+		{
+			name: "running build",
+			summary: testBuild(0,
+				testStepAt("build", constants.StatusSuccess, 110, 10*time.Second),
+				testStepAt("test", constants.StatusRunning, 130, 20*time.Second),
+			),
+			running: 30 * time.Second,
+			idle:    30 * time.Second,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := newBreakdown(test.summary)
+
+			if got.Pending != 2*time.Second {
+				t.Errorf("Pending is %s, want %s", got.Pending, 2*time.Second)
+			}
+
+			if got.Queued != 8*time.Second {
+				t.Errorf("Queued is %s, want %s", got.Queued, 8*time.Second)
+			}
+
+			if got.Running != test.running {
+				t.Errorf("Running is %s, want %s", got.Running, test.running)
+			}
+
+			if got.Idle != test.idle {
+				t.Errorf("Idle is %s, want %s", got.Idle, test.idle)
+			}
+
+			// the breakdown adds up to the time since the build was created
+			if got.Total() != 70*time.Second {
+				t.Errorf("Total is %s, want %s", got.Total(), 70*time.Second)
+			}
+		})
+	}
+}
+
+func TestNewBreakdown_Queued(t *testing.T) {
+	// setup types
+	s := testBuild(0, testStepAt("build", constants.StatusPending, 0, 0))
+	s.Totals.Status = constants.StatusPending
+	s.Totals.Started = 0
+	s.Totals.Enqueued = time.Now().Unix() - 60
+
+	got := newBreakdown(s)
+
+	if got.Queued < time.Minute {
+		t.Errorf("Queued is %s, want at least %s", got.Queued, time.Minute)
+	}
+
+	if got.Running != 0 || got.Idle != 0 {
+		t.Errorf("breakdown is %s, want no time running", got)
+	}
+}
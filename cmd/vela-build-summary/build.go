@@ -9,14 +9,14 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/gosuri/uitable"
 	"github.com/sirupsen/logrus"
-
-	api "github.com/go-vela/server/api/types"
 )
 
 // Build represents the plugin configuration for build information.
 type Build struct {
 	// number for the build
 	Number int
+	// number of the step running the plugin
+	Step int
 }
 
 // Validate verifies the Build is properly configured.
@@ -40,22 +40,35 @@ func buildRate(duration string, size uint64) int64 {
 	// calculate the timestamp duration in seconds
 	s := (float64(d) / float64(time.Second))
 
+	// check if the build ran for any duration
+	//
+	// this avoids dividing by zero for a build that hasn't
+	// started running or finished in less than a second
+	if s <= 0 {
+		return 0
+	}
+
 	// return the rate of bytes per second
 	return int64(float64(size) / s)
 }
 
 // buildRow is a helper function to produce a build row in the build summary table.
-func buildRow(table *uitable.Table, b *api.Build, buildLines *int, buildSize *uint64) {
+//
+// The name column for the build notes the number of services and
+// steps excluded from the totals for the build, if there are any.
+func buildRow(table *uitable.Table, b *Resource, excluded int, compare bool) {
 	logrus.Debug("adding build information to build summary table")
 
-	// calculate duration based off the build timestamps
-	duration := b.Duration()
+	// create a variable to track the note for the build
+	var note string
 
-	// calculate rate based off build duration and size
-	rate := fmt.Sprintf("%d B/s", buildRate(duration, *buildSize))
+	// check if any resources were excluded from the totals
+	if excluded > 0 {
+		note = fmt.Sprintf("(%d excluded)", excluded)
+	}
 
 	// add a row to the table with the specified values
 	//
 	// https://pkg.go.dev/github.com/gosuri/uitable?tab=doc#Table.AddRow
-	table.AddRow("build", "", b.GetNumber(), b.GetStatus(), duration, *buildLines, humanize.Bytes(*buildSize), rate)
+	table.AddRow(append([]any{"build", note, b.Number, b.Status, b.Wait(), b.Elapsed(), b.Lines, humanize.Bytes(b.Size), b.Throughput(), ""}, compareCells(b, compare)...)...)
 }
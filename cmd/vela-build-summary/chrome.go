@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+// chromeDocument represents the Chrome Trace Event document
+// produced for the build summary.
+//
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type chromeDocument struct {
+	TraceEvents     []*chromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string         `json:"displayTimeUnit"`
+}
+
+// chromeEvent represents an event in the Chrome Trace Event document.
+type chromeEvent struct {
+	Name  string         `json:"name"`
+	Cat   string         `json:"cat,omitempty"`
+	Phase string         `json:"ph"`
+	TS    int64          `json:"ts"`
+	Dur   int64          `json:"dur,omitempty"`
+	PID   int            `json:"pid"`
+	TID   int            `json:"tid"`
+	CName string         `json:"cname,omitempty"`
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+// chromeMetadata is a helper function to produce a metadata
+// event naming a process or thread in the document.
+func chromeMetadata(name string, tid int, value string) *chromeEvent {
+	return &chromeEvent{
+		Name:  name,
+		Phase: "M",
+		PID:   1,
+		TID:   tid,
+		Args:  map[string]any{"name": value},
+	}
+}
+
+// chromeResourceEvent is a helper function to convert
+// a resource into a complete event for the document.
+func chromeResourceEvent(r *Resource, tid int) *chromeEvent {
+	logrus.Tracef("adding %s %s to Chrome trace document", r.Type, r.Name)
+
+	// use the current time for resources that haven't finished
+	finished := r.Finished
+	if finished == 0 {
+		finished = time.Now().Unix()
+	}
+
+	event := &chromeEvent{
+		Name:  r.Name,
+		Cat:   r.Type,
+		Phase: "X",
+		TS:    time.Unix(r.Started, 0).UnixMicro(),
+		Dur:   (time.Duration(finished-r.Started) * time.Second).Microseconds(),
+		PID:   1,
+		TID:   tid,
+		Args: map[string]any{
+			"status":     r.Status,
+			"exit_code":  r.ExitCode,
+			"image":      r.Image,
+			"log_bytes":  r.Size,
+			"log_errors": r.Errors,
+			"log_lines":  r.Lines,
+		},
+	}
+
+	// check if the resource ran in the previous build
+	if r.Previous != nil {
+		event.Args["previous_status"] = r.Previous.Status
+		event.Args["previous_duration_seconds"] = int64(r.Previous.Duration.Seconds())
+		event.Args["previous_log_bytes"] = r.Previous.Size
+		event.Args["previous_log_lines"] = r.Previous.Lines
+		event.Args["duration_delta_seconds"] = int64((r.Duration - r.Previous.Duration).Seconds())
+		event.Args["log_bytes_delta"] = int64(r.Size) - int64(r.Previous.Size) //nolint:gosec // log sizes don't overflow an int64
+		event.Args["log_lines_delta"] = r.Lines - r.Previous.Lines
+	}
+
+	// check if the resource regressed from the previous build
+	if len(r.Regressions) > 0 {
+		event.Args["regressions"] = r.Regressions
+	}
+
+	// check if the resource didn't run in the previous build
+	if r.New {
+		event.Args["new"] = true
+	}
+
+	// mark failed resources with a distinct color and category
+	switch r.Status {
+	case constants.StatusFailure, constants.StatusError, constants.StatusKilled:
+		event.Cat = fmt.Sprintf("%s,%s", r.Type, r.Status)
+		event.CName = "terrible"
+	}
+
+	return event
+}
+
+// chromeOutput is a helper function to output the provided build
+// summary in the Chrome Trace Event format.
+//
+// Each service is given its own track, and each stage is given a
+// track with the steps that ran in that stage. The document can
+// be loaded into chrome://tracing or https://ui.perfetto.dev.
+//
+// If the build was compared against a previous build, the values
+// from and change from the previous build are added to the args
+// for each event.
+func chromeOutput(w io.Writer, s *Summary) error {
+	logrus.Debug("creating Chrome trace document for build summary")
+
+	// create the document with the process for the build
+	doc := &chromeDocument{
+		TraceEvents: []*chromeEvent{
+			chromeMetadata("process_name", 0, fmt.Sprintf("%s #%d", s.Build.GetRepo().GetFullName(), s.Totals.Number)),
+		},
+		DisplayTimeUnit: "ms",
+	}
+
+	// create a variable to track the next track for the document
+	tid := 1
+
+	// check if the build started running
+	if s.Totals.Started > 0 {
+		event := chromeResourceEvent(s.Totals, tid)
+		event.Name = fmt.Sprintf("build #%d", s.Totals.Number)
+
+		// check if the build was compared against a previous build
+		if s.Previous != nil {
+			event.Args["previous_build"] = compareBuild(s)
+		}
+
+		doc.TraceEvents = append(doc.TraceEvents, chromeMetadata("thread_name", tid, "build"), event)
+
+		tid++
+	}
+
+	// add a track for each service
+	for _, r := range s.Services {
+		// check if the service started running
+		if r.Started == 0 {
+			continue
+		}
+
+		doc.TraceEvents = append(doc.TraceEvents,
+			chromeMetadata("thread_name", tid, fmt.Sprintf("service: %s", r.Name)),
+			chromeResourceEvent(r, tid),
+		)
+
+		tid++
+	}
+
+	// create a map to track the track for each stage
+	stages := make(map[string]int)
+
+	// add the steps to the track for their stage
+	for _, r := range s.Steps {
+		// check if the step started running
+		if r.Started == 0 {
+			continue
+		}
+
+		stage, ok := stages[r.Stage]
+		if !ok {
+			// use a generic name for steps without a stage
+			name := "steps"
+			if len(r.Stage) > 0 {
+				name = fmt.Sprintf("stage: %s", r.Stage)
+			}
+
+			stage = tid
+			stages[r.Stage] = stage
+
+			doc.TraceEvents = append(doc.TraceEvents, chromeMetadata("thread_name", stage, name))
+
+			tid++
+		}
+
+		doc.TraceEvents = append(doc.TraceEvents, chromeResourceEvent(r, stage))
+	}
+
+	// serialize the document as JSON
+	//
+	// https://pkg.go.dev/encoding/json#NewEncoder
+	return json.NewEncoder(w).Encode(doc)
+}
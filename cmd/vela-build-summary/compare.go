@@ -0,0 +1,474 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/sdk-go/vela"
+	api "github.com/go-vela/server/api/types"
+	"github.com/go-vela/server/constants"
+)
+
+// comparePages represents the maximum number of pages of
+// builds searched for the previous build to compare against.
+const comparePages = 5
+
+// regressionMinimum represents the minimum increase in duration
+// for a resource to be marked as a regression, so resources that
+// only run for a few seconds aren't marked for small fluctuations.
+const regressionMinimum = 5 * time.Second
+
+// regressionMarker represents the marker displayed
+// next to a delta for a resource that regressed.
+const regressionMarker = "▲"
+
+// Compare represents the plugin configuration for compare information.
+type Compare struct {
+	// enables comparing the build against the previous build
+	Enabled bool
+	// percentage a metric must increase by to be marked as a regression
+	Threshold float64
+}
+
+// Validate verifies the Compare is properly configured.
+func (c *Compare) Validate() error {
+	logrus.Trace("validating compare plugin configuration")
+
+	// verify threshold is provided
+	//
+	// the threshold is also used by the diff command,
+	// so it's verified even if comparing isn't enabled
+	if c.Threshold < 0 {
+		return fmt.Errorf("invalid compare threshold provided: %v", c.Threshold)
+	}
+
+	return nil
+}
+
+// completed is a helper function to determine if the provided
+// build ran to completion, so it can be compared against.
+func completed(b *api.Build) bool {
+	switch b.GetStatus() {
+	case constants.StatusSuccess, constants.StatusFailure:
+		return b.GetFinished() > 0
+	default:
+		return false
+	}
+}
+
+// previous is a helper function to capture the most recent completed
+// build for the same repo, branch and event as the provided build.
+//
+// Builds that were canceled, killed or errored are skipped, since they
+// didn't run to completion. A nil build is returned if no previous build
+// is found within the first pages of builds.
+func (p *Plugin) previous(client *vela.Client, build *api.Build) (*api.Build, error) {
+	logrus.Infof("capturing previous %s build for branch %s", build.GetEvent(), build.GetBranch())
+
+	// set the filters for the list of builds
+	//
+	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#BuildListOptions
+	opts := &vela.BuildListOptions{
+		Branch: build.GetBranch(),
+		Event:  build.GetEvent(),
+		Before: build.GetCreated(),
+		ListOptions: vela.ListOptions{
+			Page:    1,
+			PerPage: perPage,
+		},
+	}
+
+	for page := 1; page <= comparePages; page++ {
+		opts.Page = page
+
+		// send API call to capture a list of builds
+		//
+		// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#BuildService.GetAll
+		builds, resp, err := client.Build.GetAll(p.Repo.Org, p.Repo.Name, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		// iterate through all builds in the list
+		//
+		// the builds are ordered from newest to oldest
+		for _, b := range *builds {
+			// check if the build is before the provided build and completed
+			if b.GetNumber() < build.GetNumber() && completed(&b) {
+				return &b, nil
+			}
+		}
+
+		// check if there is another page of builds
+		if linkPage(resp, "next") == 0 {
+			break
+		}
+	}
+
+	return nil, nil
+}
+
+// compare is a helper function to capture the most recent completed
+// build and compare the provided build summary against it.
+//
+// A comparison is optional for the build summary, so a warning is
+// logged rather than failing the plugin if it can't be captured.
+func (p *Plugin) compare(ctx context.Context, client *vela.Client, s *Summary) {
+	// capture the previous build to compare against
+	prev, err := p.previous(client, s.Build)
+	if err != nil {
+		logrus.Warnf("unable to capture previous build for comparison: %v", err)
+
+		return
+	}
+
+	// check if a previous build was found
+	if prev == nil {
+		logrus.Infof("no previous %s build found for branch %s to compare against", s.Build.GetEvent(), s.Build.GetBranch())
+
+		return
+	}
+
+	// capture the build summary for the previous build
+	base, err := p.capture(ctx, client, p.Repo, prev.GetNumber())
+	if err != nil {
+		logrus.Warnf("unable to capture previous build %d for comparison: %v", prev.GetNumber(), err)
+
+		return
+	}
+
+	s.compare(base, p.Compare.Threshold)
+}
+
+// compareKey is a helper function to produce the key used
+// to match a resource against the previous build.
+//
+// Steps may share a name across stages, so the stage is
+// included in the key along with the type and name.
+func compareKey(r *Resource) string {
+	return fmt.Sprintf("%s/%s/%s", r.Type, r.Stage, r.Name)
+}
+
+// plugin is a helper function to determine if the provided
+// resource matches the step running the plugin by name.
+func (s *Summary) plugin(r *Resource) bool {
+	// check if the step running the plugin was captured
+	if s.Plugin == nil || r.Type != "step" {
+		return false
+	}
+
+	return r.Name == s.Plugin.GetName() && r.Stage == s.Plugin.GetStage()
+}
+
+// compare is a helper function to match the services, steps and stages
+// in the build summary against the provided build summary by name, and
+// mark the metrics that regressed by more than the threshold percentage.
+//
+// The step running the plugin is excluded from both builds, since it's
+// still running and would always be reported as changed from the base.
+func (s *Summary) compare(base *Summary, threshold float64) {
+	logrus.Debugf("comparing build summary against build %d", base.Totals.Number)
+
+	s.Previous = base
+
+	// create an index of the resources in the previous build
+	index := make(map[string]*Resource)
+
+	for _, r := range base.Resources() {
+		// check if the resource is the step running the plugin
+		if s.plugin(r) {
+			continue
+		}
+
+		index[compareKey(r)] = r
+	}
+
+	for _, stage := range base.Stages {
+		index[compareKey(stage.Totals)] = stage.Totals
+	}
+
+	// create a list of the resources to compare
+	resources := append(s.Resources(), s.Totals)
+
+	for _, stage := range s.Stages {
+		resources = append(resources, stage.Totals)
+	}
+
+	// iterate through all resources to compare
+	for _, r := range resources {
+		// check if the resource is the step running the plugin
+		if s.plugin(r) {
+			continue
+		}
+
+		// match the build totals regardless of the name
+		prev := base.Totals
+		if r != s.Totals {
+			prev = index[compareKey(r)]
+		}
+
+		// check if the resource is new in the build
+		if prev == nil {
+			r.New = true
+
+			continue
+		}
+
+		delete(index, compareKey(prev))
+
+		r.Previous = prev
+		r.Regressions = regressions(r, prev, threshold)
+	}
+
+	// capture the services and steps that were removed from the build
+	for _, r := range base.Resources() {
+		if _, ok := index[compareKey(r)]; ok {
+			s.Removed = append(s.Removed, r)
+		}
+	}
+}
+
+// regressions is a helper function to capture the metrics for the provided
+// resource that increased by more than the threshold percentage.
+//
+// Only resources that are complete in both builds are compared, since
+// the metrics for other resources are based off partial information.
+func regressions(r, prev *Resource, threshold float64) []string {
+	// check if the resource is complete in both builds
+	if !r.Complete() || !prev.Complete() {
+		return nil
+	}
+
+	// create a function to check if a metric increased by more than the threshold
+	increased := func(value, base float64) bool {
+		return base > 0 && value > base*(1+threshold/100)
+	}
+
+	var result []string
+
+	// check if the duration regressed
+	if increased(float64(r.Duration), float64(prev.Duration)) && r.Duration-prev.Duration >= regressionMinimum {
+		result = append(result, "duration")
+	}
+
+	// check if the log lines regressed
+	if increased(float64(r.Lines), float64(prev.Lines)) {
+		result = append(result, "log_lines")
+	}
+
+	// check if the log size regressed
+	if increased(float64(r.Size), float64(prev.Size)) {
+		result = append(result, "log_bytes")
+	}
+
+	return result
+}
+
+// mark is a helper function to add the regression
+// marker to the provided delta if the metric regressed.
+func (r *Resource) mark(delta, metric string) string {
+	// check if the metric regressed
+	if slices.Contains(r.Regressions, metric) {
+		return fmt.Sprintf("%s %s", delta, regressionMarker)
+	}
+
+	return delta
+}
+
+// DurationDelta returns the change in duration from the previous build formatted for display.
+//
+// A "new" is returned for resources that didn't run in the previous build,
+// and a "-" is returned for resources that weren't compared.
+func (r *Resource) DurationDelta() string {
+	// check if the resource ran in the previous build
+	if r.New {
+		return "new"
+	}
+
+	// check if the resource was compared
+	if r.Previous == nil {
+		return "-"
+	}
+
+	// calculate the change in duration
+	d := r.Duration - r.Previous.Duration
+
+	switch {
+	case d > 0:
+		return r.mark(fmt.Sprintf("+%s", d), "duration")
+	case d < 0:
+		return fmt.Sprintf("-%s", -d)
+	default:
+		return "0s"
+	}
+}
+
+// LinesDelta returns the change in log lines from the previous build formatted for display.
+//
+// A "-" is returned for resources that didn't run in the previous build or weren't compared.
+func (r *Resource) LinesDelta() string {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return "-"
+	}
+
+	return r.mark(fmt.Sprintf("%+d", r.Lines-r.Previous.Lines), "log_lines")
+}
+
+// SizeDelta returns the change in log size from the previous build formatted for display.
+//
+// A "-" is returned for resources that didn't run in the previous build or weren't compared.
+func (r *Resource) SizeDelta() string {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return "-"
+	}
+
+	switch {
+	case r.Size > r.Previous.Size:
+		return r.mark(fmt.Sprintf("+%s", humanize.Bytes(r.Size-r.Previous.Size)), "log_bytes")
+	case r.Size < r.Previous.Size:
+		return fmt.Sprintf("-%s", humanize.Bytes(r.Previous.Size-r.Size))
+	default:
+		return "0 B"
+	}
+}
+
+// PreviousStatus returns the status from the previous build formatted for display.
+//
+// A "-" is returned for resources that didn't run in the previous build or weren't compared.
+func (r *Resource) PreviousStatus() string {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return "-"
+	}
+
+	return r.Previous.Status
+}
+
+// PreviousElapsed returns the duration from the previous build formatted for display.
+//
+// A "-" is returned for resources that didn't run in the previous build or weren't compared.
+func (r *Resource) PreviousElapsed() string {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return "-"
+	}
+
+	return r.Previous.Elapsed()
+}
+
+// PreviousLines returns the log lines from the previous build formatted for display.
+//
+// A "-" is returned for resources that didn't run in the previous build or weren't compared.
+func (r *Resource) PreviousLines() string {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return "-"
+	}
+
+	return strconv.Itoa(r.Previous.Lines)
+}
+
+// PreviousSize returns the log size from the previous build formatted for display.
+//
+// A "-" is returned for resources that didn't run in the previous build or weren't compared.
+func (r *Resource) PreviousSize() string {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return "-"
+	}
+
+	return humanize.Bytes(r.Previous.Size)
+}
+
+// compareCells is a helper function to produce the cells with the values
+// from the previous build next to the change from the previous build for
+// a resource in the build summary table.
+//
+// No cells are produced if the build isn't compared against a previous build.
+func compareCells(r *Resource, compare bool) []any {
+	// check if the build is compared against a previous build
+	if !compare {
+		return nil
+	}
+
+	return []any{r.PreviousStatus(), r.PreviousElapsed(), r.PreviousLines(), r.PreviousSize(), r.DurationDelta(), r.LinesDelta(), r.SizeDelta()}
+}
+
+// compareBuild is a helper function to produce the name of the build the
+// build summary was compared against, including the repo for the build if
+// it ran for a different repo (i.e. #1 or octocat/hello-world#1).
+func compareBuild(s *Summary) string {
+	// capture the repo for both builds
+	repo, prev := s.Build.GetRepo(), s.Previous.Build.GetRepo()
+
+	// check if the builds ran for different repos
+	if repo.GetOrg() != prev.GetOrg() || repo.GetName() != prev.GetName() {
+		return fmt.Sprintf("%s/%s#%d", prev.GetOrg(), prev.GetName(), s.Previous.Totals.Number)
+	}
+
+	return fmt.Sprintf("#%d", s.Previous.Totals.Number)
+}
+
+// compareNote is a helper function to produce the note for the
+// comparison with the services and steps that regressed from or
+// changed status since the previous build, and the ones that were
+// added to or removed from it.
+func compareNote(s *Summary) string {
+	// create variables to track the regressed, changed, added and removed resources
+	var regressed, changed, added, removed []string
+
+	for _, r := range s.Resources() {
+		// check if the resource regressed
+		if len(r.Regressions) > 0 {
+			regressed = append(regressed, fmt.Sprintf("%s (%s)", r.Name, strings.Join(r.Regressions, ", ")))
+		}
+
+		// check if the resource changed status
+		if r.Previous != nil && r.Previous.Status != r.Status {
+			changed = append(changed, fmt.Sprintf("%s (%s -> %s)", r.Name, r.Previous.Status, r.Status))
+		}
+
+		// check if the resource was added
+		if r.New {
+			added = append(added, r.Name)
+		}
+	}
+
+	for _, r := range s.Removed {
+		removed = append(removed, r.Name)
+	}
+
+	// check if any resources regressed
+	note := "no regressions"
+	if len(regressed) > 0 {
+		note = fmt.Sprintf("regressed %s", strings.Join(regressed, "; "))
+	}
+
+	// check if any resources changed status
+	if len(changed) > 0 {
+		note = fmt.Sprintf("%s, changed %s", note, strings.Join(changed, "; "))
+	}
+
+	// check if any resources were added
+	if len(added) > 0 {
+		note = fmt.Sprintf("%s, added %s", note, strings.Join(added, ", "))
+	}
+
+	// check if any resources were removed
+	if len(removed) > 0 {
+		note = fmt.Sprintf("%s, removed %s", note, strings.Join(removed, ", "))
+	}
+
+	return note
+}
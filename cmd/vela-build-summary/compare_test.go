@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	api "github.com/go-vela/server/api/types"
+	"github.com/go-vela/server/constants"
+)
+
+// testSummary is a helper function to produce a build summary
+// for the provided build number with the provided steps.
+func testSummary(number int, steps ...*Resource) *Summary {
+	return &Summary{
+		Totals: &Resource{Type: "build", Number: number, Status: constants.StatusSuccess},
+		Steps:  steps,
+		Stages: newStages(steps),
+	}
+}
+
+// testStep is a helper function to produce a step with the provided
+// name, status and duration for a build summary.
+func testStep(name, status string, duration time.Duration) *Resource {
+	r := &Resource{Type: "step", Name: name, Stage: "test", Status: status, Started: 1, Duration: duration}
+
+	// check if the step completed
+	if status != constants.StatusRunning {
+		r.Finished = 1 + int64(duration.Seconds())
+	}
+
+	return r
+}
+
+func TestSummary_Compare(t *testing.T) {
+	// setup types
+	plugin := new(api.Step)
+	plugin.SetName("summary")
+	plugin.SetStage("test")
+	plugin.SetNumber(3)
+
+	// setup tests
+	tests := []struct {
+		name    string
+		head    *Summary
+		base    *Summary
+		plugin  *api.Step
+		deltas  []string
+		removed []string
+		note    string
+	}{
+		{
+			name: "unchanged",
+			head: testSummary(2, testStep("build", constants.StatusSuccess, time.Minute)),
+			base: testSummary(1, testStep("build", constants.StatusSuccess, time.Minute)),
+			deltas: []string{
+				"0s",
+			},
+			note: "no regressions",
+		},
+		{
+			name: "regressed",
+			head: testSummary(2, testStep("build", constants.StatusSuccess, 2*time.Minute)),
+			base: testSummary(1, testStep("build", constants.StatusSuccess, time.Minute)),
+			deltas: []string{
+				"+1m0s ▲",
+			},
+			note: "regressed build (duration)",
+		},
+		{
+			name: "below minimum",
+			head: testSummary(2, testStep("build", constants.StatusSuccess, 4*time.Second)),
+			base: testSummary(1, testStep("build", constants.StatusSuccess, time.Second)),
+			deltas: []string{
+				"+3s",
+			},
+			note: "no regressions",
+		},
+		{
+			name: "added and removed",
+			head: testSummary(2, testStep("build", constants.StatusFailure, time.Minute), testStep("lint", constants.StatusSuccess, time.Minute)),
+			base: testSummary(1, testStep("build", constants.StatusSuccess, time.Minute), testStep("vet", constants.StatusSuccess, time.Minute)),
+			deltas: []string{
+				"0s",
+				"new",
+			},
+			removed: []string{"vet"},
+			note:    "no regressions, changed build (success -> failure), added lint, removed vet",
+		},
+		{
+			name:   "plugin step",
+			head:   testSummary(2, testStep("build", constants.StatusSuccess, time.Minute), testStep("summary", constants.StatusRunning, time.Second)),
+			base:   testSummary(1, testStep("build", constants.StatusSuccess, time.Minute), testStep("summary", constants.StatusSuccess, time.Minute)),
+			plugin: plugin,
+			deltas: []string{
+				"0s",
+				"-",
+			},
+			note: "no regressions",
+		},
+		{
+			name:   "plugin step excluded by wait",
+			head:   testSummary(2, testStep("build", constants.StatusSuccess, time.Minute)),
+			base:   testSummary(1, testStep("build", constants.StatusSuccess, time.Minute), testStep("summary", constants.StatusSuccess, time.Minute)),
+			plugin: plugin,
+			deltas: []string{
+				"0s",
+			},
+			note: "no regressions",
+		},
+		{
+			name: "plugin step not running the plugin",
+			head: testSummary(2, testStep("build", constants.StatusSuccess, time.Minute), testStep("summary", constants.StatusRunning, time.Second)),
+			base: testSummary(1, testStep("build", constants.StatusSuccess, time.Minute), testStep("summary", constants.StatusSuccess, time.Minute)),
+			deltas: []string{
+				"0s",
+				"-59s",
+			},
+			note: "no regressions, changed summary (success -> running)",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.head.Plugin = test.plugin
+
+			test.head.compare(test.base, 10)
+
+			if test.head.Previous != test.base {
+				t.Errorf("Previous is %v, want %v", test.head.Previous, test.base)
+			}
+
+			var deltas []string
+
+			for _, r := range test.head.Steps {
+				deltas = append(deltas, r.DurationDelta())
+			}
+
+			if !slices.Equal(deltas, test.deltas) {
+				t.Errorf("deltas are %v, want %v", deltas, test.deltas)
+			}
+
+			var removed []string
+
+			for _, r := range test.head.Removed {
+				removed = append(removed, r.Name)
+			}
+
+			if !slices.Equal(removed, test.removed) {
+				t.Errorf("Removed is %v, want %v", removed, test.removed)
+			}
+
+			if got := compareNote(test.head); got != test.note {
+				t.Errorf("compareNote is %q, want %q", got, test.note)
+			}
+		})
+	}
+}
+
+func TestPlugin_Self(t *testing.T) {
+	// setup types
+	step := new(api.Step)
+	step.SetName("summary")
+	step.SetNumber(3)
+
+	steps := &[]api.Step{*step}
+
+	// setup tests
+	tests := []struct {
+		name   string
+		repo   *Repo
+		number int
+		step   int
+		want   bool
+	}{
+		{
+			name:   "build running the plugin",
+			repo:   &Repo{Org: "foo", Name: "bar"},
+			number: 2,
+			step:   3,
+			want:   true,
+		},
+		{
+			name:   "different build",
+			repo:   &Repo{Org: "foo", Name: "bar"},
+			number: 1,
+			step:   3,
+		},
+		{
+			name:   "different repo",
+			repo:   &Repo{Org: "foo", Name: "baz"},
+			number: 2,
+			step:   3,
+		},
+		{
+			name:   "no step",
+			repo:   &Repo{Org: "foo", Name: "bar"},
+			number: 2,
+		},
+		{
+			name:   "missing step",
+			repo:   &Repo{Org: "foo", Name: "bar"},
+			number: 2,
+			step:   4,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &Plugin{
+				Build: &Build{Number: 2, Step: test.step},
+				Repo:  &Repo{Org: "foo", Name: "bar"},
+			}
+
+			got := p.self(test.repo, test.number, steps)
+
+			if (got != nil) != test.want {
+				t.Errorf("self is %v, want step %v", got, test.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -17,6 +20,16 @@ type Config struct {
 	AppName string
 	// the app version utilizing this config
 	AppVersion string
+	// maximum number of concurrent API calls to the Vela server
+	Concurrency int
+	// maximum bytes of memory used for analyzing logs
+	LogMemory uint64
+	// maximum duration to wait for the Vela server to respond to each attempt
+	ResponseTimeout time.Duration
+	// total number of attempts for each API call to the Vela server
+	RetryAttempts int
+	// maximum duration to wait between attempts for an API call
+	RetryMaxWait time.Duration
 	// Vela server to interact with
 	Server string
 	// user token to authenticate with the Vela server
@@ -24,14 +37,38 @@ type Config struct {
 }
 
 // New creates a Vela client for capturing build information.
-func (c *Config) New() (*vela.Client, error) {
+//
+// All API calls sent with the client are canceled
+// when the provided context is canceled.
+func (c *Config) New(ctx context.Context) (*vela.Client, error) {
 	logrus.Trace("creating new Vela client from plugin configuration")
 
 	// create the app string
 	appID := fmt.Sprintf("%s; %s", c.AppName, c.AppVersion)
 
+	// create HTTP transport limiting the time to wait for each attempt
+	//
+	// https://pkg.go.dev/net/http#Transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = c.ResponseTimeout
+
+	// create HTTP client sending every request with the context
+	// and retrying requests that fail with a transient error
+	//
+	// https://pkg.go.dev/net/http#Client
+	httpClient := &http.Client{
+		Transport: &contextTransport{
+			ctx: ctx,
+			next: &retryTransport{
+				attempts: c.RetryAttempts,
+				maxWait:  c.RetryMaxWait,
+				next:     transport,
+			},
+		},
+	}
+
 	// create Vela client from configuration
-	client, err := vela.NewClient(c.Server, appID, nil)
+	client, err := vela.NewClient(c.Server, appID, httpClient)
 	if err != nil {
 		return nil, err
 	}
@@ -62,6 +99,31 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid config server provided: %s", c.Server)
 	}
 
+	// verify concurrency is provided
+	if c.Concurrency < 1 {
+		return fmt.Errorf("invalid config concurrency provided: %d", c.Concurrency)
+	}
+
+	// verify log memory is provided
+	if c.LogMemory == 0 {
+		return fmt.Errorf("no config log memory provided")
+	}
+
+	// verify response timeout is provided
+	if c.ResponseTimeout <= 0 {
+		return fmt.Errorf("invalid config response timeout provided: %s", c.ResponseTimeout)
+	}
+
+	// verify retry attempts are provided
+	if c.RetryAttempts < 1 {
+		return fmt.Errorf("invalid config retry attempts provided: %d", c.RetryAttempts)
+	}
+
+	// verify retry max wait is provided
+	if c.RetryMaxWait <= 0 {
+		return fmt.Errorf("invalid config retry max wait provided: %s", c.RetryMaxWait)
+	}
+
 	// verify token is provided
 	if len(c.Token) == 0 {
 		return fmt.Errorf("no config token provided")
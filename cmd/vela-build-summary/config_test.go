@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		config  *Config
+		failure bool
+	}{
+		{
+			name:   "success",
+			config: &Config{Concurrency: 1, LogMemory: minLogMemory, ResponseTimeout: time.Second, RetryAttempts: 1, RetryMaxWait: time.Second, Server: "https://vela.example.com", Token: "foo"},
+		},
+		{
+			name:    "no response timeout",
+			config:  &Config{Concurrency: 1, LogMemory: minLogMemory, RetryAttempts: 1, RetryMaxWait: time.Second, Server: "https://vela.example.com", Token: "foo"},
+			failure: true,
+		},
+		{
+			name:    "no retry attempts",
+			config:  &Config{Concurrency: 1, LogMemory: minLogMemory, ResponseTimeout: time.Second, RetryMaxWait: time.Second, Server: "https://vela.example.com", Token: "foo"},
+			failure: true,
+		},
+		{
+			name:    "no retry max wait",
+			config:  &Config{Concurrency: 1, LogMemory: minLogMemory, ResponseTimeout: time.Second, RetryAttempts: 1, Server: "https://vela.example.com", Token: "foo"},
+			failure: true,
+		},
+		{
+			name:    "negative retry max wait",
+			config:  &Config{Concurrency: 1, LogMemory: minLogMemory, ResponseTimeout: time.Second, RetryAttempts: 1, RetryMaxWait: -time.Second, Server: "https://vela.example.com", Token: "foo"},
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Validate()
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("Validate should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Validate returned err: %v", err)
+			}
+		})
+	}
+}
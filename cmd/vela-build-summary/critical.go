@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/sdk-go/vela"
+	api "github.com/go-vela/server/api/types"
+)
+
+// stageNeeds is a helper function to capture the stages each
+// stage needs from the pipeline compiled for the build.
+//
+// The needs are only used to analyze the critical path of the
+// build, so a warning is logged and the default needs for Vela
+// are used if the pipeline can't be captured. The pipeline isn't
+// captured for pipelines using steps rather than stages.
+func (p *Plugin) stageNeeds(client *vela.Client, repo *Repo, build *api.Build, steps *[]api.Step) map[string][]string {
+	// check if the steps ran in stages
+	if !slices.ContainsFunc(*steps, func(s api.Step) bool { return len(s.GetStage()) > 0 }) {
+		return nil
+	}
+
+	logrus.Debugf("capturing pipeline for build %s/%s/%d", repo.Org, repo.Name, build.GetNumber())
+
+	// send API call to capture the compiled pipeline for the build
+	//
+	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#PipelineService.Compile
+	// the pipeline is requested as JSON since the Vela SDK
+	// doesn't decode the stages from the YAML output
+	pipeline, _, err := client.Pipeline.Compile(repo.Org, repo.Name, build.GetCommit(), &vela.PipelineOptions{Output: "json"})
+	if err != nil {
+		logrus.Warnf("unable to capture pipeline, using default stage needs for critical path: %v", err)
+
+		return nil
+	}
+
+	needs := make(map[string][]string)
+
+	for _, stage := range pipeline.Stages {
+		needs[stage.Name] = stage.Needs
+	}
+
+	return needs
+}
+
+// defaultNeeds is a helper function to capture the stages the
+// provided stage needs when they aren't found in the pipeline.
+//
+// Vela runs the init stage first, followed by the clone stage,
+// and every other stage needs the clone stage by default.
+func defaultNeeds(stage string) []string {
+	switch stage {
+	case "init":
+		return nil
+	case "clone":
+		return []string{"init"}
+	default:
+		return []string{"clone"}
+	}
+}
+
+// criticalGraph represents the dependencies between the
+// steps in a build used to analyze the critical path.
+type criticalGraph struct {
+	// steps that ran in the build ordered by number
+	steps []*Resource
+	// steps each step waited for before it started
+	deps map[*Resource][]*Resource
+	// steps waiting for each step before they started
+	next map[*Resource][]*Resource
+	// slack calculated for each step
+	slack map[*Resource]time.Duration
+	// unix timestamp for when the last step finished
+	end int64
+}
+
+// finish is a helper function to capture when the provided step
+// finished, or how long a step that is still running has run for.
+func finish(r *Resource) int64 {
+	return max(r.Finished, r.Started+int64(r.Duration.Seconds()))
+}
+
+// newCriticalGraph is a helper function to create the dependencies
+// between the steps in a build from the stages and their needs.
+//
+// Steps within a stage run in order, so each step depends on the step
+// before it. The first step in a stage depends on the last step of each
+// stage it needs. For pipelines using steps rather than stages, every
+// step depends on the step before it.
+func newCriticalGraph(s *Summary, needs map[string][]string) *criticalGraph {
+	g := &criticalGraph{
+		deps:  make(map[*Resource][]*Resource),
+		next:  make(map[*Resource][]*Resource),
+		slack: make(map[*Resource]time.Duration),
+	}
+
+	// iterate through all steps that ran in stage order
+	for _, r := range criticalOrder(s) {
+		// check if the step started running
+		if r.Started == 0 {
+			continue
+		}
+
+		g.steps = append(g.steps, r)
+		g.end = max(g.end, finish(r))
+	}
+
+	// create a variable to track the last step that ran in each stage
+	last := make(map[string]*Resource)
+
+	for _, r := range g.steps {
+		last[r.Stage] = r
+	}
+
+	// add a dependency between the provided steps
+	depend := func(r, dep *Resource) {
+		g.deps[r] = append(g.deps[r], dep)
+		g.next[dep] = append(g.next[dep], r)
+	}
+
+	// create a function to capture the needs for a stage
+	needsFor := func(stage string) []string {
+		deps, ok := needs[stage]
+		if !ok {
+			return defaultNeeds(stage)
+		}
+
+		return deps
+	}
+
+	// create a function to capture the last steps that ran for a
+	// stage, following its needs if no steps in the stage ran
+	var tails func(stage string, seen map[string]bool) []*Resource
+
+	tails = func(stage string, seen map[string]bool) []*Resource {
+		// check if the stage was already visited
+		if seen[stage] {
+			return nil
+		}
+
+		seen[stage] = true
+
+		// check if a step in the stage ran
+		if r, ok := last[stage]; ok {
+			return []*Resource{r}
+		}
+
+		var result []*Resource
+
+		for _, need := range needsFor(stage) {
+			result = append(result, tails(need, seen)...)
+		}
+
+		return result
+	}
+
+	// iterate through all steps that ran to add their dependencies
+	for i, r := range g.steps {
+		switch {
+		// check if this is the first step that ran
+		case i == 0:
+		// check if the pipeline doesn't use stages or the
+		// step before this one ran in the same stage
+		case len(s.Stages) == 0 || g.steps[i-1].Stage == r.Stage:
+			depend(r, g.steps[i-1])
+		default:
+			seen := map[string]bool{r.Stage: true}
+
+			for _, need := range needsFor(r.Stage) {
+				for _, dep := range tails(need, seen) {
+					depend(r, dep)
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+// criticalOrder is a helper function to order the steps in a
+// build so the steps in a stage are ordered after the steps in
+// the stages before it.
+func criticalOrder(s *Summary) []*Resource {
+	// check if the pipeline doesn't use stages
+	if len(s.Stages) == 0 {
+		return s.Steps
+	}
+
+	var steps []*Resource
+
+	for _, stage := range s.Stages {
+		steps = append(steps, stage.Steps...)
+	}
+
+	return steps
+}
+
+// ready is a helper function to capture when the provided
+// step was ready to run because all of its dependencies finished.
+func (g *criticalGraph) ready(r *Resource) int64 {
+	var ready int64
+
+	for _, dep := range g.deps[r] {
+		ready = max(ready, finish(dep))
+	}
+
+	return ready
+}
+
+// slackFor is a helper function to calculate the duration the provided
+// step could have been delayed without delaying the end of the build.
+//
+// The slack for a step is the smallest slack across the steps waiting
+// for it, plus the time between when it finished and when each of
+// those steps was ready to run. A step nothing waits for has the
+// time between when it finished and when the last step finished.
+func (g *criticalGraph) slackFor(r *Resource) time.Duration {
+	// check if the slack for the step was already calculated
+	if slack, ok := g.slack[r]; ok {
+		return slack
+	}
+
+	// start from the time until the last step finished
+	slack := time.Duration(g.end-finish(r)) * time.Second
+
+	// check if any steps waited for the step
+	if len(g.next[r]) > 0 {
+		slack = time.Duration(math.MaxInt64)
+
+		for _, n := range g.next[r] {
+			gap := time.Duration(max(g.ready(n)-finish(r), 0)) * time.Second
+
+			slack = min(slack, gap+g.slackFor(n))
+		}
+	}
+
+	g.slack[r] = max(slack, 0)
+
+	return g.slack[r]
+}
+
+// critical is a helper function to analyze the critical path of
+// the build, the chain of steps that determined when it finished.
+//
+// The critical path is found by starting from the step that finished
+// last and following the dependency that finished last for each step,
+// since that dependency is what kept the step from starting sooner.
+// Every step that ran is given the slack it had, so only shortening
+// the steps on the critical path shortens the build.
+func (s *Summary) critical(needs map[string][]string) {
+	logrus.Debug("analyzing critical path for build summary")
+
+	g := newCriticalGraph(s, needs)
+
+	// calculate the slack for every step that ran
+	for _, r := range g.steps {
+		r.Slack = g.slackFor(r)
+	}
+
+	// create a variable to track the step that finished last
+	var r *Resource
+
+	for _, step := range g.steps {
+		if r == nil || finish(step) >= finish(r) {
+			r = step
+		}
+	}
+
+	// walk backwards through the dependency that finished last
+	for r != nil {
+		r.Critical = true
+		s.CriticalPath = append([]*Resource{r}, s.CriticalPath...)
+
+		var dep *Resource
+
+		for _, d := range g.deps[r] {
+			if dep == nil || finish(d) >= finish(dep) {
+				dep = d
+			}
+		}
+
+		r = dep
+	}
+}
+
+// criticalSpan is a helper function to calculate the wall-clock span
+// of the critical path, from when the first step on it started until
+// the last step on it finished.
+func criticalSpan(s *Summary) time.Duration {
+	// check if the build has a critical path
+	if len(s.CriticalPath) == 0 {
+		return 0
+	}
+
+	return time.Duration(finish(s.CriticalPath[len(s.CriticalPath)-1])-s.CriticalPath[0].Started) * time.Second
+}
+
+// criticalNames is a helper function to capture the
+// names of the steps on the critical path of the build.
+func criticalNames(s *Summary) []string {
+	names := make([]string, 0, len(s.CriticalPath))
+
+	for _, r := range s.CriticalPath {
+		names = append(names, r.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-vela/sdk-go/vela"
+	api "github.com/go-vela/server/api/types"
+	"github.com/go-vela/server/constants"
+)
+
+// testRun is a helper function to produce a step with the provided
+// number and stage that ran between the provided unix timestamps.
+//
+// A step with no start never started running.
+func testRun(number int, stage string, started, finished int64) *Resource {
+	return &Resource{
+		Type:     "step",
+		Name:     fmt.Sprintf("step%d", number),
+		Number:   number,
+		Stage:    stage,
+		Status:   constants.StatusSuccess,
+		Started:  started,
+		Finished: finished,
+		Duration: time.Duration(finished-started) * time.Second,
+	}
+}
+
+func TestSummary_Critical(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		steps    []*Resource
+		stages   bool
+		needs    map[string][]string
+		critical []string
+		slack    map[string]time.Duration
+	}{
+		{
+			name: "steps",
+			steps: []*Resource{
+				testRun(1, "", 100, 102),
+				testRun(2, "", 104, 110),
+				testRun(3, "", 110, 115),
+			},
+			critical: []string{"step1", "step2", "step3"},
+			slack:    map[string]time.Duration{"step1": 0, "step2": 0, "step3": 0},
+		},
+		{
+			name: "parallel stages",
+			steps: []*Resource{
+				testRun(1, "init", 100, 101),
+				testRun(2, "clone", 101, 102),
+				testRun(3, "test", 102, 110),
+				testRun(4, "lint", 102, 105),
+			},
+			stages:   true,
+			critical: []string{"step1", "step2", "step3"},
+			slack:    map[string]time.Duration{"step1": 0, "step2": 0, "step3": 0, "step4": 5 * time.Second},
+		},
+		{
+			name: "stage needs ran no steps",
+			steps: []*Resource{
+				testRun(1, "init", 100, 101),
+				testRun(2, "clone", 101, 102),
+				testRun(3, "build", 0, 0),
+				testRun(4, "test", 102, 110),
+			},
+			stages: true,
+			needs: map[string][]string{
+				"build": {"clone"},
+				"test":  {"build"},
+			},
+			critical: []string{"step1", "step2", "step4"},
+			slack:    map[string]time.Duration{"step1": 0, "step2": 0, "step4": 0},
+		},
+		{
+			name: "stage needs multiple stages",
+			steps: []*Resource{
+				testRun(1, "init", 100, 101),
+				testRun(2, "clone", 101, 102),
+				testRun(3, "test", 102, 106),
+				testRun(4, "lint", 102, 109),
+				testRun(5, "publish", 109, 112),
+			},
+			stages: true,
+			needs: map[string][]string{
+				"publish": {"test", "lint"},
+			},
+			critical: []string{"step1", "step2", "step4", "step5"},
+			slack:    map[string]time.Duration{"step3": 3 * time.Second, "step4": 0, "step5": 0},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Summary{Steps: test.steps}
+
+			if test.stages {
+				s.Stages = newStages(test.steps)
+			}
+
+			s.critical(test.needs)
+
+			if got := criticalNames(s); !slices.Equal(got, test.critical) {
+				t.Errorf("CriticalPath is %v, want %v", got, test.critical)
+			}
+
+			for _, r := range s.Steps {
+				want, ok := test.slack[r.Name]
+				if !ok {
+					continue
+				}
+
+				if r.Slack != want {
+					t.Errorf("Slack for %s is %v, want %v", r.Name, r.Slack, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCriticalGraph_Tails(t *testing.T) {
+	// setup types
+	clone := testRun(2, "clone", 101, 102)
+	test := testRun(4, "test", 102, 110)
+
+	s := &Summary{
+		Steps: []*Resource{
+			testRun(1, "init", 100, 101),
+			clone,
+			testRun(3, "build", 0, 0),
+			test,
+		},
+	}
+	s.Stages = newStages(s.Steps)
+
+	g := newCriticalGraph(s, map[string][]string{"build": {"clone"}, "test": {"build"}})
+
+	// the test stage needs the build stage, which ran no steps,
+	// so the test step depends on the stages the build stage needs
+	if got := g.deps[test]; len(got) != 1 || got[0] != clone {
+		t.Errorf("deps for test step are %v, want the clone step", got)
+	}
+
+	if len(g.steps) != 3 {
+		t.Errorf("steps are %d, want 3", len(g.steps))
+	}
+
+	if g.end != 110 {
+		t.Errorf("end is %d, want 110", g.end)
+	}
+}
+
+func TestPlugin_StageNeeds(t *testing.T) {
+	// setup types
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v1/pipelines/foo/bar/123/compile":
+			fmt.Fprint(w, `{"version":"1","stages":[{"name":"test","needs":["clone"]},{"name":"publish","needs":["test","lint"]}]}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":"unable to compile pipeline"}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := vela.NewClient(srv.URL, "test", nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+
+	// create a step with the provided stage
+	step := func(stage string) api.Step {
+		s := new(api.Step)
+		s.SetNumber(1)
+		s.SetStage(stage)
+
+		return *s
+	}
+
+	// setup tests
+	tests := []struct {
+		name   string
+		commit string
+		steps  []api.Step
+		want   map[string][]string
+	}{
+		{
+			name:   "stages",
+			commit: "123",
+			steps:  []api.Step{step("test")},
+			want:   map[string][]string{"test": {"clone"}, "publish": {"test", "lint"}},
+		},
+		{
+			name:   "compile failure",
+			commit: "456",
+			steps:  []api.Step{step("test")},
+		},
+		{
+			name:   "steps",
+			commit: "123",
+			steps:  []api.Step{step("")},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			build := new(api.Build)
+			build.SetNumber(1)
+			build.SetCommit(test.commit)
+
+			p := &Plugin{}
+
+			got := p.stageNeeds(client, &Repo{Org: "foo", Name: "bar"}, build, &test.steps)
+
+			if len(got) != len(test.want) {
+				t.Fatalf("stageNeeds is %v, want %v", got, test.want)
+			}
+
+			for stage, needs := range test.want {
+				if !slices.Equal(got[stage], needs) {
+					t.Errorf("needs for %s are %v, want %v", stage, got[stage], needs)
+				}
+			}
+		})
+	}
+}
+
+func TestSummary_Critical_CompileFailure(t *testing.T) {
+	// setup types
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error":"unable to compile pipeline"}`)
+	}))
+	defer srv.Close()
+
+	client, err := vela.NewClient(srv.URL, "test", nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+
+	build := new(api.Build)
+	build.SetNumber(1)
+
+	step := new(api.Step)
+	step.SetStage("test")
+
+	needs := (&Plugin{}).stageNeeds(client, &Repo{Org: "foo", Name: "bar"}, build, &[]api.Step{*step})
+
+	// the default needs run every stage after the clone stage,
+	// so the stages run in parallel after the clone step
+	s := &Summary{
+		Steps: []*Resource{
+			testRun(1, "init", 100, 101),
+			testRun(2, "clone", 101, 102),
+			testRun(3, "test", 102, 110),
+			testRun(4, "publish", 102, 104),
+		},
+	}
+	s.Stages = newStages(s.Steps)
+
+	s.critical(needs)
+
+	if got, want := criticalNames(s), []string{"step1", "step2", "step3"}; !slices.Equal(got, want) {
+		t.Errorf("CriticalPath is %v, want %v", got, want)
+	}
+
+	if got, want := s.Steps[3].Slack, 6*time.Second; got != want {
+		t.Errorf("Slack for publish step is %v, want %v", got, want)
+	}
+}
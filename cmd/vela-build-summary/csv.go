@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// csvRecord is a helper function to convert a resource
+// into a record for the CSV document.
+//
+// The log rate is zero for resources that aren't complete, since
+// the rate isn't meaningful until the resource finished running.
+func csvRecord(r *Resource) []string {
+	var rate int64
+
+	// check if the resource is complete
+	if r.Complete() {
+		rate = r.Rate
+	}
+
+	return []string{
+		r.Type,
+		r.Name,
+		strconv.Itoa(r.Number),
+		r.Status,
+		r.Stage,
+		strconv.FormatInt(int64(r.Duration.Seconds()), 10),
+		strconv.Itoa(r.Lines),
+		strconv.FormatUint(r.Size, 10),
+		strconv.Itoa(r.Errors),
+		strconv.FormatInt(rate, 10),
+	}
+}
+
+// csvDelta is a helper function to convert the change for a resource
+// from the previous build into values for the CSV document.
+//
+// Empty values are produced for resources that didn't run in the previous build.
+func csvDelta(r *Resource) []string {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return []string{"", "", "", "", ""}
+	}
+
+	return []string{
+		strconv.FormatInt(int64((r.Duration - r.Previous.Duration).Seconds()), 10),
+		strconv.Itoa(r.Lines - r.Previous.Lines),
+		strconv.FormatInt(int64(r.Size)-int64(r.Previous.Size), 10), //nolint:gosec // log sizes don't overflow an int64
+		r.Previous.Status,
+		strings.Join(r.Regressions, ";"),
+	}
+}
+
+// csvOutput is a helper function to output the provided build summary
+// as a document of delimiter-separated values.
+//
+// The document includes one record for each service, step and the build
+// with raw numeric values rather than humanized ones, so the build summary
+// can be loaded into spreadsheets. The comma is used to separate values
+// for CSV, and a tab is used to separate values for TSV.
+//
+// If the build was compared against a previous build, the change from
+// the previous build is added to each record.
+func csvOutput(w io.Writer, s *Summary, comma rune) error {
+	logrus.Debug("creating CSV document for build summary")
+
+	// create a new writer for the document
+	//
+	// https://pkg.go.dev/encoding/csv#NewWriter
+	writer := csv.NewWriter(w)
+
+	// set the delimiter for the values in the document
+	writer.Comma = comma
+
+	logrus.Trace("adding headers to CSV document")
+	// set of build fields we display in the document
+	headers := []string{"type", "name", "number", "status", "stage", "duration_seconds", "log_lines", "log_bytes", "log_errors", "log_rate_bytes_per_second"}
+
+	// check if the build was compared against a previous build
+	if s.Previous != nil {
+		headers = append(headers, "duration_delta_seconds", "log_lines_delta", "log_bytes_delta", "previous_status", "regressions")
+	}
+
+	records := [][]string{headers}
+
+	// add the service, step and build records to the document
+	for _, r := range append(s.Resources(), s.Totals) {
+		record := csvRecord(r)
+
+		// check if the build was compared against a previous build
+		if s.Previous != nil {
+			record = append(record, csvDelta(r)...)
+		}
+
+		records = append(records, record)
+	}
+
+	// write all records to the document
+	//
+	// https://pkg.go.dev/encoding/csv#Writer.WriteAll
+	return writer.WriteAll(records)
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-vela/server/constants"
+)
+
+func TestCSVOutput(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name  string
+		comma rune
+		want  string
+	}{
+		{
+			name:  "csv",
+			comma: ',',
+			want: `type,name,number,status,stage,duration_seconds,log_lines,log_bytes,log_errors,log_rate_bytes_per_second
+service,postgres,1,success,,60,1,23,0,0
+step,clone,1,success,init,10,2,37,0,3
+step,test,2,failure,test,40,2,33,1,0
+step,publish,3,skipped,publish,0,0,0,0,0
+build,,1,failure,,60,5,93,1,1
+`,
+		},
+		{
+			name:  "tsv",
+			comma: '\t',
+			want: "type\tname\tnumber\tstatus\tstage\tduration_seconds\tlog_lines\tlog_bytes\tlog_errors\tlog_rate_bytes_per_second\n" +
+				"service\tpostgres\t1\tsuccess\t\t60\t1\t23\t0\t0\n" +
+				"step\tclone\t1\tsuccess\tinit\t10\t2\t37\t0\t3\n" +
+				"step\ttest\t2\tfailure\ttest\t40\t2\t33\t1\t0\n" +
+				"step\tpublish\t3\tskipped\tpublish\t0\t0\t0\t0\t0\n" +
+				"build\t\t1\tfailure\t\t60\t5\t93\t1\t1\n",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+
+			err := csvOutput(buf, testBuildSummary(), test.comma)
+			if err != nil {
+				t.Fatalf("csvOutput returned err: %v", err)
+			}
+
+			if buf.String() != test.want {
+				t.Errorf("csvOutput is %q, want %q", buf.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestCSVOutput_Running(t *testing.T) {
+	// setup types
+	s := testSummary(1, testStep("test", constants.StatusRunning, 10*time.Second))
+	s.Steps[0].Rate = 100
+
+	buf := new(bytes.Buffer)
+
+	err := csvOutput(buf, s, ',')
+	if err != nil {
+		t.Fatalf("csvOutput returned err: %v", err)
+	}
+
+	want := `type,name,number,status,stage,duration_seconds,log_lines,log_bytes,log_errors,log_rate_bytes_per_second
+step,test,0,running,test,10,0,0,0,0
+build,,1,success,,0,0,0,0,0
+`
+
+	if buf.String() != want {
+		t.Errorf("csvOutput is %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCSVOutput_Compare(t *testing.T) {
+	// setup types
+	head := testSummary(2, testStep("test", constants.StatusFailure, time.Minute), testStep("lint", constants.StatusSuccess, time.Minute))
+	base := testSummary(1, testStep("test", constants.StatusSuccess, 30*time.Second))
+
+	head.compare(base, 10)
+
+	buf := new(bytes.Buffer)
+
+	err := csvOutput(buf, head, ',')
+	if err != nil {
+		t.Fatalf("csvOutput returned err: %v", err)
+	}
+
+	want := `type,name,number,status,stage,duration_seconds,log_lines,log_bytes,log_errors,log_rate_bytes_per_second,duration_delta_seconds,log_lines_delta,log_bytes_delta,previous_status,regressions
+step,test,0,failure,test,60,0,0,0,0,30,0,0,success,duration
+step,lint,0,success,test,60,0,0,0,0,,,,,
+build,,2,success,,0,0,0,0,0,0,0,0,success,
+`
+
+	if buf.String() != want {
+		t.Errorf("csvOutput is %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/go-vela/sdk-go/vela"
+)
+
+// Target represents a build targeted by the diff command.
+type Target struct {
+	// repo the build ran for
+	Repo *Repo
+	// number for the build
+	Number int
+}
+
+// String returns the target formatted for display (i.e. octocat/hello-world#1).
+func (t *Target) String() string {
+	return fmt.Sprintf("%s/%s#%d", t.Repo.Org, t.Repo.Name, t.Number)
+}
+
+// Validate verifies the Target is properly configured.
+func (t *Target) Validate() error {
+	logrus.Trace("validating diff target plugin configuration")
+
+	// verify number is provided
+	if t.Number <= 0 {
+		return fmt.Errorf("no diff build number provided")
+	}
+
+	return t.Repo.Validate()
+}
+
+// Diff represents the plugin configuration for diff information.
+type Diff struct {
+	// build to compare against
+	Base *Target
+	// build to compare
+	Head *Target
+}
+
+// Validate verifies the Diff is properly configured.
+func (d *Diff) Validate() error {
+	logrus.Trace("validating diff plugin configuration")
+
+	// verify base build is provided
+	if d.Base == nil {
+		return fmt.Errorf("no diff base build provided")
+	}
+
+	// verify head build is provided
+	if d.Head == nil {
+		return fmt.Errorf("no diff head build provided")
+	}
+
+	// validate base build configuration
+	err := d.Base.Validate()
+	if err != nil {
+		return err
+	}
+
+	// validate head build configuration
+	return d.Head.Validate()
+}
+
+// parseTarget is a helper function to parse a build provided to the diff
+// command in the form of a number (i.e. 1) or a repo and number (i.e.
+// octocat/hello-world#1). The provided repo is used if no repo is provided.
+func parseTarget(ref string, repo *Repo) (*Target, error) {
+	logrus.Tracef("parsing diff build %s", ref)
+
+	target := &Target{Repo: repo}
+
+	// capture the number for the build
+	number := ref
+
+	// check if the build includes a repo
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		org, name, ok := strings.Cut(ref[:i], "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid diff build provided: %s", ref)
+		}
+
+		target.Repo = &Repo{Org: org, Name: name}
+		number = ref[i+1:]
+	}
+
+	// parse the number for the build
+	//
+	// https://pkg.go.dev/strconv#Atoi
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid diff build provided: %s", ref)
+	}
+
+	target.Number = n
+
+	return target, nil
+}
+
+// diff executes the diff command based off the configuration provided.
+func diff(ctx context.Context, c *cli.Command) error {
+	// check if both builds were provided
+	if c.Args().Len() != 2 {
+		return fmt.Errorf("invalid diff builds provided: expected <base> <head>, got %d arguments", c.Args().Len())
+	}
+
+	// create the plugin
+	p, err := plugin(c)
+	if err != nil {
+		return err
+	}
+
+	// parse the build to compare against
+	base, err := parseTarget(c.Args().Get(0), p.Repo)
+	if err != nil {
+		return err
+	}
+
+	// parse the build to compare
+	head, err := parseTarget(c.Args().Get(1), p.Repo)
+	if err != nil {
+		return err
+	}
+
+	// diff configuration
+	p.Diff = &Diff{
+		Base: base,
+		Head: head,
+	}
+
+	// waiting only applies to the build running the plugin
+	p.Wait = &Wait{}
+
+	// validate the plugin
+	err = p.Validate()
+	if err != nil {
+		return err
+	}
+
+	// execute the plugin
+	return p.Exec(ctx)
+}
+
+// diff is a helper function to capture the build summary for both builds
+// and compare the head build against the base build.
+//
+// Unlike comparing against the previous build, a failure to capture either
+// build is returned since the comparison is the point of the command.
+func (p *Plugin) diff(ctx context.Context, client *vela.Client) (*Summary, error) {
+	logrus.Infof("comparing build %s against build %s", p.Diff.Head, p.Diff.Base)
+
+	// capture the build summary for the build to compare against
+	base, err := p.capture(ctx, client, p.Diff.Base.Repo, p.Diff.Base.Number)
+	if err != nil {
+		return nil, fmt.Errorf("unable to capture build %s: %w", p.Diff.Base, err)
+	}
+
+	// capture the build summary for the build to compare
+	head, err := p.capture(ctx, client, p.Diff.Head.Repo, p.Diff.Head.Number)
+	if err != nil {
+		return nil, fmt.Errorf("unable to capture build %s: %w", p.Diff.Head, err)
+	}
+
+	head.compare(base, p.Compare.Threshold)
+
+	return head, nil
+}
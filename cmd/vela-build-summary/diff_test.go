@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-vela/server/constants"
+)
+
+func TestParseTarget(t *testing.T) {
+	// setup types
+	repo := &Repo{Org: "foo", Name: "bar"}
+
+	// setup tests
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		failure bool
+	}{
+		{
+			name: "number",
+			ref:  "41",
+			want: "foo/bar#41",
+		},
+		{
+			name: "repo and number",
+			ref:  "octocat/hello-world#42",
+			want: "octocat/hello-world#42",
+		},
+		{
+			name:    "repo without name",
+			ref:     "octocat#42",
+			failure: true,
+		},
+		{
+			name:    "invalid number",
+			ref:     "octocat/hello-world#foo",
+			failure: true,
+		},
+		{
+			name:    "empty",
+			ref:     "",
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTarget(test.ref, repo)
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("parseTarget should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("parseTarget returned err: %v", err)
+			}
+
+			if got.String() != test.want {
+				t.Errorf("parseTarget is %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPlugin_Validate_Diff(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		diff    *Diff
+		format  string
+		failure bool
+	}{
+		{
+			name:   "table",
+			diff:   &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format: formatTable,
+		},
+		{
+			name:   "html",
+			diff:   &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format: formatHTML,
+		},
+		{
+			name:   "junit",
+			diff:   &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format: formatJUnit,
+		},
+		{
+			name:   "chrome",
+			diff:   &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format: formatChrome,
+		},
+		{
+			name:   "mermaid",
+			diff:   &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format: formatMermaid,
+		},
+		{
+			name:   "otlp",
+			diff:   &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format: formatOTLP,
+		},
+		{
+			name:   "prometheus",
+			diff:   &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format: formatPrometheus,
+		},
+		{
+			name:    "no head build",
+			diff:    &Diff{Base: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 1}},
+			format:  formatTable,
+			failure: true,
+		},
+		{
+			name:    "invalid base build",
+			diff:    &Diff{Base: &Target{Repo: &Repo{Org: "foo"}, Number: 1}, Head: &Target{Repo: &Repo{Org: "foo", Name: "bar"}, Number: 2}},
+			format:  formatTable,
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &Plugin{
+				Build:   &Build{},
+				Compare: &Compare{Threshold: 10},
+				Config:  &Config{Concurrency: 1, LogMemory: minLogMemory, ResponseTimeout: time.Second, RetryAttempts: 1, RetryMaxWait: time.Second, Server: "https://vela.example.com", Token: "foo"},
+				Diff:    test.diff,
+				Outputs: []*Output{{Format: test.format}},
+				Repo:    &Repo{},
+				Wait:    &Wait{},
+			}
+
+			err := p.Validate()
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("Validate should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Validate returned err: %v", err)
+			}
+		})
+	}
+}
+
+func TestOutput_Render_Diff(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{
+			format: formatTable,
+			want: []string{
+				"BASE STATUS BASE DURATION BASE LOG LINES BASE LOG SIZE Δ DURATION Δ LOG LINES Δ LOG SIZE",
+				"step test 2 failure 20s 40s 2 33 B 0 B/s critical success 30s 2 33 B +10s ▲ +0 0 B",
+			},
+		},
+		{
+			format: formatMarkdown,
+			want: []string{
+				"| Base Status | Base Duration | Base Log Lines | Base Log Size | Δ Duration | Δ Log Lines | Δ Log Size |",
+				"| step | test | 2 | ❌ failure | 20s | 40s | 2 | 33 B | 0 B/s | critical | ✅ success | 30s | 2 | 33 B | +10s ▲ | +0 | 0 B |",
+			},
+		},
+		{
+			format: formatHTML,
+			want: []string{
+				"<th>Base Status</th>",
+			},
+		},
+		{
+			format: formatChrome,
+			want: []string{
+				`"previous_build":"#1"`,
+				`"previous_duration_seconds":30`,
+				`"regressions":["duration"]`,
+			},
+		},
+		{
+			format: formatJUnit,
+			want: []string{
+				`<property name="previous_build" value="#1"></property>`,
+				`<property name="previous_duration_seconds" value="30"></property>`,
+				`<property name="regressions" value="duration"></property>`,
+			},
+		},
+		{
+			format: formatMermaid,
+			want: []string{
+				"title foo/bar build 2 compared to foo/bar build 1",
+				"test ▲ :crit, step-2, 1020, 40s",
+			},
+		},
+		{
+			format: formatOTLP,
+			want: []string{
+				`{"key":"vela.previous.build","value":{"stringValue":"#1"}}`,
+				`{"key":"vela.previous.duration_seconds","value":{"intValue":"30"}}`,
+				`{"key":"vela.regressions","value":{"stringValue":"duration"}}`,
+			},
+		},
+		{
+			format: formatPrometheus,
+			want: []string{
+				`vela_step_previous_duration_seconds{org="foo",repo="bar",build="2",step="test",stage="test",previous_build="1"} 30`,
+				`vela_step_regression{org="foo",repo="bar",build="2",step="test",stage="test",previous_build="1",metric="duration"} 1`,
+				`vela_step_regression{org="foo",repo="bar",build="2",step="test",stage="test",previous_build="1",metric="log_lines"} 0`,
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			head := testBuildSummary()
+			head.Build.SetNumber(2)
+			head.Build.GetRepo().SetFullName("foo/bar")
+			head.Totals.Number = 2
+
+			base := testBuildSummary()
+			base.Build.GetRepo().SetFullName("foo/bar")
+
+			// the test step passed faster in the base build
+			base.Steps[1].Status = constants.StatusSuccess
+			base.Steps[1].Duration = 30 * time.Second
+			base.Stages = newStages(base.Steps)
+
+			head.compare(base, 10)
+
+			buf := new(bytes.Buffer)
+
+			err := (&Output{Format: test.format}).render(buf, head)
+			if err != nil {
+				t.Fatalf("render returned err: %v", err)
+			}
+
+			// ignore the padding between the columns
+			got := strings.Join(strings.Fields(buf.String()), " ")
+
+			for _, want := range test.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("render is missing %q", want)
+				}
+			}
+		})
+	}
+}
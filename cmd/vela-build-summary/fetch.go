@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/sdk-go/vela"
+)
+
+// perPage represents the number of resources
+// requested per page from the Vela server.
+const perPage = 100
+
+// fetcher represents a group of API calls sent
+// concurrently to the Vela server.
+//
+// The number of API calls in flight at once is bounded by the
+// concurrency for the fetcher, and the first task to fail cancels
+// the context for the remaining tasks in the group.
+type fetcher struct {
+	// context the API calls are sent with
+	ctx context.Context
+	// function to cancel the remaining tasks in the group
+	cancel context.CancelFunc
+	// semaphore bounding the API calls in flight
+	sem chan struct{}
+
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// newFetcher creates a fetcher with the provided
+// context and maximum number of concurrent API calls.
+func newFetcher(ctx context.Context, concurrency int) *fetcher {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &fetcher{
+		ctx:    ctx,
+		cancel: cancel,
+		sem:    make(chan struct{}, max(concurrency, 1)),
+	}
+}
+
+// Go runs the provided task in the group.
+func (f *fetcher) Go(task func() error) {
+	f.wg.Add(1)
+
+	go func() {
+		defer f.wg.Done()
+
+		err := task()
+		if err != nil {
+			f.mu.Lock()
+			f.errs = append(f.errs, err)
+			f.mu.Unlock()
+
+			// cancel the remaining tasks in the group
+			f.cancel()
+		}
+	}()
+}
+
+// Wait blocks until all tasks in the group are
+// complete and returns the errors from the tasks.
+//
+// Errors caused by canceling the remaining tasks are omitted,
+// so only the failures are returned. If every task was canceled,
+// the error for the first task is returned.
+func (f *fetcher) Wait() error {
+	f.wg.Wait()
+	f.cancel()
+
+	// create a variable to track the failures
+	var errs []error
+
+	for _, err := range f.errs {
+		// check if the task was canceled
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			continue
+		}
+
+		errs = append(errs, err)
+	}
+
+	// check if all tasks were canceled
+	if len(errs) == 0 && len(f.errs) > 0 {
+		return f.errs[0]
+	}
+
+	return errors.Join(errs...)
+}
+
+// call sends the provided API call once the number of
+// API calls in flight is below the concurrency limit.
+func (f *fetcher) call(fn func() error) error {
+	select {
+	case f.sem <- struct{}{}:
+	case <-f.ctx.Done():
+		return f.ctx.Err()
+	}
+
+	defer func() { <-f.sem }()
+
+	return fn()
+}
+
+// linkPage is a helper function to capture a page of resources
+// from the pagination information in the response for a page.
+//
+// The pagination information isn't always populated on the
+// response by the Vela SDK, so the Link header is parsed
+// directly when it is missing.
+//
+// https://datatracker.ietf.org/doc/html/rfc8288
+func linkPage(resp *vela.Response, rel string) int {
+	// check if the response is available
+	if resp == nil {
+		return 0
+	}
+
+	// check if the page was populated by the Vela SDK
+	switch {
+	case rel == "next" && resp.NextPage > 0:
+		return resp.NextPage
+	case rel == "last" && resp.LastPage > 0:
+		return resp.LastPage
+	}
+
+	// check if the HTTP response is available
+	if resp.Response == nil {
+		return 0
+	}
+
+	// iterate through all links in the Link header
+	for _, link := range strings.Split(resp.Header.Get("Link"), ",") {
+		// split the link into the target and parameters
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if !ok || !strings.Contains(params, `rel="`+rel+`"`) {
+			continue
+		}
+
+		// parse the URL for the target of the link
+		u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			continue
+		}
+
+		page, err := strconv.Atoi(u.Query().Get("page"))
+		if err != nil {
+			continue
+		}
+
+		return page
+	}
+
+	return 0
+}
+
+// paginate is a helper function to capture every page of a list of
+// resources from the Vela server using the pagination information
+// from the response for each page.
+//
+// When the last page is known from the first page, the remaining
+// pages are captured concurrently with the provided fetcher.
+//
+// If the first page can't be captured, the error is returned. If a
+// later page can't be captured, a warning is logged and the resources
+// captured up to that point are returned, so the build summary is
+// still produced with the information that was available.
+func paginate[T any](f *fetcher, kind string, list func(opts *vela.ListOptions) (*[]T, *vela.Response, error)) (*[]T, error) {
+	logrus.Tracef("capturing all pages of %s", kind)
+
+	// fetch is a helper function to capture a single page of resources
+	fetch := func(page int) ([]T, *vela.Response, error) {
+		var (
+			resources *[]T
+			resp      *vela.Response
+		)
+
+		err := f.call(func() (err error) {
+			logrus.Tracef("capturing page %d of %s", page, kind)
+
+			// set the pagination options for list of resources
+			//
+			// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#ListOptions
+			resources, resp, err = list(&vela.ListOptions{
+				Page:    page,
+				PerPage: perPage,
+			})
+
+			return err
+		})
+		if err != nil || resources == nil {
+			return nil, resp, err
+		}
+
+		return *resources, resp, nil
+	}
+
+	// capture the first page of resources
+	resources, resp, err := fetch(1)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a variable to track the current page
+	page := 1
+
+	// check if the last page is known
+	if last := linkPage(resp, "last"); last > page {
+		logrus.Tracef("capturing pages 2-%d of %s concurrently", last, kind)
+
+		var wg sync.WaitGroup
+
+		// create variables to track the result for each remaining page
+		pages := make([][]T, last-page)
+		resps := make([]*vela.Response, last-page)
+		errs := make([]error, last-page)
+
+		for i := range pages {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				pages[i], resps[i], errs[i] = fetch(page + i + 1)
+			}()
+		}
+
+		wg.Wait()
+
+		// check if the remaining tasks were canceled
+		err = f.ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range pages {
+			// check if the page was captured
+			if errs[i] != nil {
+				logrus.Warnf("unable to capture page %d of %s, build summary may be incomplete: %v", page+i+1, kind, errs[i])
+
+				return &resources, nil
+			}
+
+			resources = append(resources, pages[i]...)
+		}
+
+		page, resp = last, resps[len(resps)-1]
+	}
+
+	// capture any pages that follow sequentially
+	for {
+		// check if there is another page of resources
+		//
+		// guard against a next page that doesn't move forward
+		next := linkPage(resp, "next")
+		if next <= page {
+			break
+		}
+
+		var result []T
+
+		result, resp, err = fetch(next)
+		if err != nil {
+			// check if the remaining tasks were canceled
+			if f.ctx.Err() != nil {
+				return nil, f.ctx.Err()
+			}
+
+			logrus.Warnf("unable to capture page %d of %s, build summary may be incomplete: %v", next, kind, err)
+
+			break
+		}
+
+		resources = append(resources, result...)
+		page = next
+	}
+
+	logrus.Debugf("captured %d %s", len(resources), kind)
+
+	return &resources, nil
+}
@@ -0,0 +1,355 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/go-vela/sdk-go/vela"
+)
+
+// testResponse is a helper function to produce a response
+// from the Vela server with the provided Link header.
+func testResponse(link string) *vela.Response {
+	header := http.Header{}
+
+	if len(link) > 0 {
+		header.Set("Link", link)
+	}
+
+	return &vela.Response{Response: &http.Response{Header: header}}
+}
+
+// testLink is a helper function to produce a Link header
+// with a link for each of the provided relations and pages.
+func testLink(pages map[string]int) string {
+	var links []string
+
+	for _, rel := range []string{"first", "prev", "next", "last"} {
+		if page, ok := pages[rel]; ok {
+			links = append(links, fmt.Sprintf(`<https://vela.example.com/api/v1/repos/foo/bar/builds/1/steps?page=%d&per_page=100>; rel="%s"`, page, rel))
+		}
+	}
+
+	return strings.Join(links, ", ")
+}
+
+func TestLinkPage(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name string
+		resp *vela.Response
+		rel  string
+		want int
+	}{
+		{
+			name: "no response",
+			rel:  "next",
+		},
+		{
+			name: "next page from the Vela SDK",
+			resp: &vela.Response{NextPage: 2},
+			rel:  "next",
+			want: 2,
+		},
+		{
+			name: "last page from the Vela SDK",
+			resp: &vela.Response{LastPage: 5},
+			rel:  "last",
+			want: 5,
+		},
+		{
+			name: "no HTTP response",
+			resp: &vela.Response{},
+			rel:  "next",
+		},
+		{
+			name: "next page",
+			resp: testResponse(testLink(map[string]int{"first": 1, "next": 3, "last": 5})),
+			rel:  "next",
+			want: 3,
+		},
+		{
+			name: "last page",
+			resp: testResponse(testLink(map[string]int{"first": 1, "next": 3, "last": 5})),
+			rel:  "last",
+			want: 5,
+		},
+		{
+			name: "no last page",
+			resp: testResponse(testLink(map[string]int{"first": 1, "next": 3})),
+			rel:  "last",
+		},
+		{
+			name: "no link header",
+			resp: testResponse(""),
+			rel:  "next",
+		},
+		{
+			name: "link without parameters",
+			resp: testResponse(`<https://vela.example.com/api/v1/repos?page=2>`),
+			rel:  "next",
+		},
+		{
+			name: "invalid url",
+			resp: testResponse(`<://vela.example.com?page=2>; rel="next"`),
+			rel:  "next",
+		},
+		{
+			name: "invalid page",
+			resp: testResponse(`<https://vela.example.com/api/v1/repos?page=foo>; rel="next", <https://vela.example.com/api/v1/repos?page=4>; rel="next"`),
+			rel:  "next",
+			want: 4,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := linkPage(test.resp, test.rel)
+
+			if got != test.want {
+				t.Errorf("linkPage is %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		pages    int
+		last     bool
+		fail     int
+		want     []int
+		warnings int
+		failure  bool
+	}{
+		{
+			name:  "single page",
+			pages: 1,
+			want:  []int{1},
+		},
+		{
+			name:  "last page known",
+			pages: 4,
+			last:  true,
+			want:  []int{1, 2, 3, 4},
+		},
+		{
+			name:  "no last page",
+			pages: 4,
+			want:  []int{1, 2, 3, 4},
+		},
+		{
+			name:     "failing middle page with last page known",
+			pages:    4,
+			last:     true,
+			fail:     3,
+			want:     []int{1, 2},
+			warnings: 1,
+		},
+		{
+			name:     "failing middle page without last page",
+			pages:    4,
+			fail:     3,
+			want:     []int{1, 2},
+			warnings: 1,
+		},
+		{
+			name:    "failing first page",
+			pages:   4,
+			fail:    1,
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+
+			// create a counter for the number of pages requested
+			var requests atomic.Int32
+
+			list := func(opts *vela.ListOptions) (*[]int, *vela.Response, error) {
+				requests.Add(1)
+
+				if opts.Page == test.fail {
+					return nil, nil, fmt.Errorf("unable to capture page %d", opts.Page)
+				}
+
+				links := map[string]int{"first": 1}
+
+				if opts.Page < test.pages {
+					links["next"] = opts.Page + 1
+				}
+
+				if test.last {
+					links["last"] = test.pages
+				}
+
+				return &[]int{opts.Page}, testResponse(testLink(links)), nil
+			}
+
+			f := newFetcher(context.Background(), 2)
+
+			got, err := paginate(f, "steps", list)
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("paginate should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("paginate returned err: %v", err)
+			}
+
+			if !slices.Equal(*got, test.want) {
+				t.Errorf("paginate is %v, want %v", *got, test.want)
+			}
+
+			// the remaining pages are all requested when the last page is known
+			if test.last && int(requests.Load()) != test.pages {
+				t.Errorf("requests are %d, want %d", requests.Load(), test.pages)
+			}
+
+			var warnings int
+
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel {
+					warnings++
+				}
+			}
+
+			if warnings != test.warnings {
+				t.Errorf("warnings are %d, want %d", warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestPaginate_Canceled(t *testing.T) {
+	// setup types
+	f := newFetcher(context.Background(), 2)
+
+	list := func(opts *vela.ListOptions) (*[]int, *vela.Response, error) {
+		// cancel the remaining tasks while capturing a later page
+		if opts.Page == 2 {
+			f.cancel()
+
+			return nil, nil, context.Canceled
+		}
+
+		return &[]int{opts.Page}, testResponse(testLink(map[string]int{"next": opts.Page + 1, "last": 3})), nil
+	}
+
+	_, err := paginate(f, "steps", list)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("paginate returned err %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestFetcher_Wait(t *testing.T) {
+	// setup types
+	errFailure := errors.New("unable to capture steps")
+
+	// setup tests
+	tests := []struct {
+		name  string
+		tasks []error
+		want  []error
+	}{
+		{
+			name:  "success",
+			tasks: []error{nil, nil},
+		},
+		{
+			name:  "failure",
+			tasks: []error{errFailure, context.Canceled, fmt.Errorf("unable to capture services: %w", context.Canceled)},
+			want:  []error{errFailure},
+		},
+		{
+			name:  "deadline exceeded",
+			tasks: []error{context.DeadlineExceeded, errFailure},
+			want:  []error{errFailure},
+		},
+		{
+			name:  "canceled",
+			tasks: []error{context.Canceled, context.Canceled},
+			want:  []error{context.Canceled},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newFetcher(context.Background(), 1)
+
+			for _, err := range test.tasks {
+				f.Go(func() error {
+					return err
+				})
+			}
+
+			err := f.Wait()
+
+			if len(test.want) == 0 {
+				if err != nil {
+					t.Errorf("Wait returned err: %v", err)
+				}
+
+				return
+			}
+
+			for _, want := range test.want {
+				if !errors.Is(err, want) {
+					t.Errorf("Wait returned err %v, want %v", err, want)
+				}
+			}
+
+			// the canceled tasks are omitted when a task failed
+			if !slices.Contains(test.want, context.Canceled) && errors.Is(err, context.Canceled) {
+				t.Errorf("Wait returned err %v for a canceled task", err)
+			}
+
+			if f.ctx.Err() == nil {
+				t.Errorf("Wait didn't cancel the context for the fetcher")
+			}
+		})
+	}
+}
+
+func TestFetcher_Call(t *testing.T) {
+	// setup types
+	f := newFetcher(context.Background(), 1)
+
+	// fill the semaphore so the call waits for the context
+	f.sem <- struct{}{}
+
+	f.cancel()
+
+	err := f.call(func() error {
+		t.Errorf("call sent the API call after the context was canceled")
+
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("call returned err %v, want %v", err, context.Canceled)
+	}
+}
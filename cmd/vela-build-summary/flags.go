@@ -0,0 +1,361 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// flags is a helper function to produce the
+// flags for configuring the plugin.
+func flags() []cli.Flag {
+	logFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "log.level",
+			Usage: "set log level - options: (trace|debug|info|warn|error|fatal|panic)",
+			Value: "info",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_LOG_LEVEL"),
+				cli.EnvVar("BUILD_SUMMARY_LOG_LEVEL"),
+				cli.File("/vela/parameters/build-summary/log_level"),
+				cli.File("/vela/secrets/build-summary/log_level"),
+			),
+		},
+	}
+
+	return slices.Concat(logFlags, buildFlags(), compareFlags(), configFlags(), outputFlags(), repoFlags(), waitFlags())
+}
+
+// buildFlags is a helper function to produce the
+// flags for the build plugin configuration.
+func buildFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "build.number",
+			Usage: "provide the number for the build",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_NUMBER"),
+				cli.EnvVar("BUILD_SUMMARY_NUMBER"),
+				cli.EnvVar("VELA_BUILD_NUMBER"),
+				cli.File("/vela/parameters/build-summary/number"),
+				cli.File("/vela/secrets/build-summary/number"),
+			),
+		},
+
+		&cli.IntFlag{
+			Name:  "build.step",
+			Usage: "provide the number for the step running the plugin",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_STEP"),
+				cli.EnvVar("BUILD_SUMMARY_STEP"),
+				cli.EnvVar("VELA_STEP_NUMBER"),
+				cli.File("/vela/parameters/build-summary/step"),
+				cli.File("/vela/secrets/build-summary/step"),
+			),
+		},
+	}
+}
+
+// compareFlags is a helper function to produce the
+// flags for the compare plugin configuration.
+func compareFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "compare.enabled",
+			Usage: "enables comparing the build against the previous build for the same branch and event",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_COMPARE"),
+				cli.EnvVar("BUILD_SUMMARY_COMPARE"),
+				cli.File("/vela/parameters/build-summary/compare"),
+				cli.File("/vela/secrets/build-summary/compare"),
+			),
+		},
+		&cli.FloatFlag{
+			Name:  "compare.threshold",
+			Usage: "percentage a duration or log metric must increase by to be marked as a regression",
+			Value: 10,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_COMPARE_THRESHOLD"),
+				cli.EnvVar("BUILD_SUMMARY_COMPARE_THRESHOLD"),
+				cli.File("/vela/parameters/build-summary/compare_threshold"),
+				cli.File("/vela/secrets/build-summary/compare_threshold"),
+			),
+		},
+	}
+}
+
+// configFlags is a helper function to produce the
+// flags for the config plugin configuration.
+func configFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "config.concurrency",
+			Usage: "maximum number of concurrent API calls to the Vela server",
+			Value: 4,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_CONCURRENCY"),
+				cli.EnvVar("BUILD_SUMMARY_CONCURRENCY"),
+				cli.File("/vela/parameters/build-summary/concurrency"),
+				cli.File("/vela/secrets/build-summary/concurrency"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "config.log_memory",
+			Usage: "maximum memory used for analyzing logs from the Vela server (i.e. 16MiB)",
+			Value: "16MiB",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_LOG_MEMORY"),
+				cli.EnvVar("BUILD_SUMMARY_LOG_MEMORY"),
+				cli.File("/vela/parameters/build-summary/log_memory"),
+				cli.File("/vela/secrets/build-summary/log_memory"),
+			),
+		},
+		&cli.DurationFlag{
+			Name:  "config.response_timeout",
+			Usage: "maximum duration to wait for the Vela server to respond to each attempt for an API call",
+			Value: 15 * time.Second,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_RESPONSE_TIMEOUT"),
+				cli.EnvVar("BUILD_SUMMARY_RESPONSE_TIMEOUT"),
+				cli.File("/vela/parameters/build-summary/response_timeout"),
+				cli.File("/vela/secrets/build-summary/response_timeout"),
+			),
+		},
+		&cli.IntFlag{
+			Name:  "config.retry_attempts",
+			Usage: "total number of attempts for each API call to the Vela server",
+			Value: 3,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_RETRY_ATTEMPTS"),
+				cli.EnvVar("BUILD_SUMMARY_RETRY_ATTEMPTS"),
+				cli.File("/vela/parameters/build-summary/retry_attempts"),
+				cli.File("/vela/secrets/build-summary/retry_attempts"),
+			),
+		},
+		&cli.DurationFlag{
+			Name:  "config.retry_max_wait",
+			Usage: "maximum duration to wait between attempts for an API call to the Vela server",
+			Value: 30 * time.Second,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_RETRY_MAX_WAIT"),
+				cli.EnvVar("BUILD_SUMMARY_RETRY_MAX_WAIT"),
+				cli.File("/vela/parameters/build-summary/retry_max_wait"),
+				cli.File("/vela/secrets/build-summary/retry_max_wait"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "config.server",
+			Usage: "Vela server to authenticate with",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_SERVER"),
+				cli.EnvVar("BUILD_SUMMARY_SERVER"),
+				cli.EnvVar("VELA_ADDR"),
+				cli.File("/vela/parameters/build-summary/server"),
+				cli.File("/vela/secrets/build-summary/server"),
+			),
+			Required: true,
+			Action: func(_ context.Context, _ *cli.Command, v string) error {
+				if strings.HasSuffix(v, "/") {
+					return fmt.Errorf("invalid server address provided: address must not have trailing slash")
+				}
+
+				return nil
+			},
+		},
+		&cli.StringFlag{
+			Name:  "config.token",
+			Usage: "user token to authenticate with the Vela server",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_TOKEN"),
+				cli.EnvVar("BUILD_SUMMARY_TOKEN"),
+				cli.EnvVar("VELA_NETRC_PASSWORD"),
+				cli.File("/vela/parameters/build-summary/token"),
+				cli.File("/vela/secrets/build-summary/token"),
+			),
+		},
+	}
+}
+
+// outputFlags is a helper function to produce the
+// flags for the output plugin configuration.
+func outputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "output.format",
+			Usage: "format to output the build summary in - options: (chrome|csv|html|json|junit|markdown|mermaid|otlp|prometheus|table|template|tsv)",
+			Value: "table",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_FORMAT"),
+				cli.EnvVar("BUILD_SUMMARY_FORMAT"),
+				cli.File("/vela/parameters/build-summary/format"),
+				cli.File("/vela/secrets/build-summary/format"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "output.directory",
+			Usage: "path to a directory to write the build summary to",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_DIRECTORY"),
+				cli.EnvVar("BUILD_SUMMARY_DIRECTORY"),
+				cli.File("/vela/parameters/build-summary/directory"),
+				cli.File("/vela/secrets/build-summary/directory"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "output.endpoint",
+			Usage: "OpenTelemetry collector to export the build summary trace to",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_ENDPOINT"),
+				cli.EnvVar("BUILD_SUMMARY_ENDPOINT"),
+				cli.File("/vela/parameters/build-summary/endpoint"),
+				cli.File("/vela/secrets/build-summary/endpoint"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "output.list",
+			Usage: "JSON list of outputs to write the build summary to",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_OUTPUTS"),
+				cli.EnvVar("BUILD_SUMMARY_OUTPUTS"),
+				cli.File("/vela/parameters/build-summary/outputs"),
+				cli.File("/vela/secrets/build-summary/outputs"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "output.path",
+			Usage: "path to a file to write the build summary to",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_PATH"),
+				cli.EnvVar("BUILD_SUMMARY_PATH"),
+				cli.File("/vela/parameters/build-summary/path"),
+				cli.File("/vela/secrets/build-summary/path"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "output.pushgateway",
+			Usage: "Prometheus Pushgateway to push the build summary metrics to",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_PUSHGATEWAY"),
+				cli.EnvVar("BUILD_SUMMARY_PUSHGATEWAY"),
+				cli.File("/vela/parameters/build-summary/pushgateway"),
+				cli.File("/vela/secrets/build-summary/pushgateway"),
+			),
+		},
+
+		&cli.StringFlag{
+			Name:  "output.template",
+			Usage: "Go template to output the build summary with",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_TEMPLATE"),
+				cli.EnvVar("BUILD_SUMMARY_TEMPLATE"),
+				cli.File("/vela/parameters/build-summary/template"),
+				cli.File("/vela/secrets/build-summary/template"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "output.template_file",
+			Usage: "path to a file containing a Go template to output the build summary with",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_TEMPLATE_FILE"),
+				cli.EnvVar("BUILD_SUMMARY_TEMPLATE_FILE"),
+				cli.File("/vela/parameters/build-summary/template_file"),
+				cli.File("/vela/secrets/build-summary/template_file"),
+			),
+		},
+		&cli.BoolFlag{
+			Name:  "output.timeline",
+			Usage: "enables outputting a timeline after the table for the build summary",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_TIMELINE"),
+				cli.EnvVar("BUILD_SUMMARY_TIMELINE"),
+				cli.File("/vela/parameters/build-summary/timeline"),
+				cli.File("/vela/secrets/build-summary/timeline"),
+			),
+		},
+		&cli.IntFlag{
+			Name:  "output.timeline_width",
+			Usage: "number of columns to fit the timeline for the build summary in",
+			Value: 120,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_TIMELINE_WIDTH"),
+				cli.EnvVar("BUILD_SUMMARY_TIMELINE_WIDTH"),
+				cli.File("/vela/parameters/build-summary/timeline_width"),
+				cli.File("/vela/secrets/build-summary/timeline_width"),
+			),
+		},
+	}
+}
+
+// repoFlags is a helper function to produce the
+// flags for the repo plugin configuration.
+func repoFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "repo.org",
+			Usage: "provide the organization name for the build",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_ORG"),
+				cli.EnvVar("BUILD_SUMMARY_ORG"),
+				cli.EnvVar("VELA_REPO_ORG"),
+				cli.File("/vela/parameters/build-summary/org"),
+				cli.File("/vela/parameters/build-summary/org"),
+			),
+		},
+		&cli.StringFlag{
+			Name:  "repo.name",
+			Usage: "provide the repository name for the build",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_REPO"),
+				cli.EnvVar("BUILD_SUMMARY_REPO"),
+				cli.EnvVar("VELA_REPO_NAME"),
+				cli.File("/vela/parameters/build-summary/repo"),
+				cli.File("/vela/parameters/build-summary/repo"),
+			),
+		},
+	}
+}
+
+// waitFlags is a helper function to produce the
+// flags for the wait plugin configuration.
+func waitFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "wait.enabled",
+			Usage: "enables waiting for the build to complete before creating the summary",
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_WAIT"),
+				cli.EnvVar("BUILD_SUMMARY_WAIT"),
+				cli.File("/vela/parameters/build-summary/wait"),
+				cli.File("/vela/secrets/build-summary/wait"),
+			),
+		},
+		&cli.DurationFlag{
+			Name:  "wait.interval",
+			Usage: "duration to wait between checking the status of the build",
+			Value: 10 * time.Second,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_WAIT_INTERVAL"),
+				cli.EnvVar("BUILD_SUMMARY_WAIT_INTERVAL"),
+				cli.File("/vela/parameters/build-summary/wait_interval"),
+				cli.File("/vela/secrets/build-summary/wait_interval"),
+			),
+		},
+		&cli.DurationFlag{
+			Name:  "wait.timeout",
+			Usage: "maximum duration to wait for the build to complete",
+			Value: 30 * time.Minute,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("PARAMETER_WAIT_TIMEOUT"),
+				cli.EnvVar("BUILD_SUMMARY_WAIT_TIMEOUT"),
+				cli.File("/vela/parameters/build-summary/wait_timeout"),
+				cli.File("/vela/secrets/build-summary/wait_timeout"),
+			),
+		},
+	}
+}
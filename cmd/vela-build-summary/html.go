@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+)
+
+// htmlTemplate represents the template used to produce
+// a self-contained HTML report for the build summary.
+//
+// All styles and scripts are inlined so the report can be
+// archived and viewed without access to external resources.
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Org }}/{{ .Repo }} build #{{ .Summary.Totals.Number }}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.5em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; text-align: left; }
+td.num { text-align: right; }
+tr.total { font-weight: bold; }
+.timeline { border: 1px solid #d0d7de; margin-bottom: 2em; padding: 8px; }
+.lane { display: flex; align-items: center; height: 22px; }
+.label { width: 220px; flex: none; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; font-size: 0.85em; }
+.track { position: relative; flex: auto; height: 16px; background: #f6f8fa; }
+.bar { position: absolute; height: 16px; min-width: 2px; cursor: pointer; border-radius: 2px; }
+.status-success { background: #2da44e; }
+.status-failure, .status-error, .status-killed { background: #cf222e; }
+.status-canceled, .status-skipped { background: #8c959f; }
+.status-running, .status-pending { background: #bf8700; }
+.badge { color: #fff; padding: 1px 6px; border-radius: 8px; font-size: 0.85em; }
+details { margin-bottom: 0.5em; }
+details.focus summary { background: #fff8c5; }
+pre { background: #f6f8fa; padding: 8px; overflow-x: auto; font-size: 0.8em; }
+</style>
+</head>
+<body>
+<h1>{{ .Org }}/{{ .Repo }} build #{{ .Summary.Totals.Number }} <span class="badge status-{{ .Summary.Totals.Status }}">{{ .Summary.Totals.Status }}</span></h1>
+<p>
+event: {{ .Summary.Build.GetEvent }} &middot;
+branch: {{ .Summary.Build.GetBranch }} &middot;
+commit: <code>{{ .Summary.Build.GetCommit }}</code> &middot;
+author: {{ .Summary.Build.GetAuthor }}
+{{- if .Summary.Build.GetLink }} &middot; <a href="{{ .Summary.Build.GetLink }}">view in Vela</a>{{ end }}
+</p>
+<p>started: {{ .Start }} &middot; generated: {{ .Generated }}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Type</th><th>Name</th><th>Number</th><th>Status</th><th>Duration</th><th>Log Lines</th><th>Log Size</th><th>Log Rate</th>{{ if .Summary.Previous }}<th>Base Status</th><th>Base Duration</th><th>Base Log Lines</th><th>Base Log Size</th><th>Δ Duration</th><th>Δ Log Lines</th><th>Δ Log Size</th>{{ end }}</tr>
+{{- range .Resources }}
+<tr><td>{{ .Type }}</td><td>{{ .Name }}</td><td class="num">{{ .Number }}</td><td><span class="badge status-{{ .Status }}">{{ .Status }}</span></td><td class="num">{{ .Elapsed }}</td><td class="num">{{ .Lines }}</td><td class="num">{{ bytes .Size }}</td><td class="num">{{ .Throughput }}</td>{{ if $.Summary.Previous }}<td>{{ with .Previous }}<span class="badge status-{{ .Status }}">{{ .Status }}</span>{{ else }}-{{ end }}</td><td class="num">{{ .PreviousElapsed }}</td><td class="num">{{ .PreviousLines }}</td><td class="num">{{ .PreviousSize }}</td><td class="num">{{ .DurationDelta }}</td><td class="num">{{ .LinesDelta }}</td><td class="num">{{ .SizeDelta }}</td>{{ end }}</tr>
+{{- end }}
+<tr class="total"><td>build</td><td>{{ if .Summary.Excluded }}({{ .Summary.Excluded }} excluded){{ end }}</td><td class="num">{{ .Summary.Totals.Number }}</td><td><span class="badge status-{{ .Summary.Totals.Status }}">{{ .Summary.Totals.Status }}</span></td><td class="num">{{ .Summary.Totals.Elapsed }}</td><td class="num">{{ .Summary.Totals.Lines }}</td><td class="num">{{ bytes .Summary.Totals.Size }}</td><td class="num">{{ .Summary.Totals.Throughput }}</td>{{ if .Summary.Previous }}<td>{{ with .Summary.Totals.Previous }}<span class="badge status-{{ .Status }}">{{ .Status }}</span>{{ else }}-{{ end }}</td><td class="num">{{ .Summary.Totals.PreviousElapsed }}</td><td class="num">{{ .Summary.Totals.PreviousLines }}</td><td class="num">{{ .Summary.Totals.PreviousSize }}</td><td class="num">{{ .Summary.Totals.DurationDelta }}</td><td class="num">{{ .Summary.Totals.LinesDelta }}</td><td class="num">{{ .Summary.Totals.SizeDelta }}</td>{{ end }}</tr>
+</table>
+{{- if .Summary.Previous }}
+<p><strong>Compared to build {{ .Compared }}</strong>: {{ .Comparison }}</p>
+{{- end }}
+
+<h2>Timeline</h2>
+<div class="timeline">
+{{- range .Resources }}
+<div class="lane">
+<div class="label" title="{{ .Type }} {{ .Name }}">{{ .Type }}: {{ .Name }}</div>
+<div class="track">{{ if .Started }}<div class="bar status-{{ .Status }}" data-log="{{ .ID }}" style="left: {{ .Offset }}%; width: {{ .Width }}%" title="{{ .Name }} ({{ .Status }}) - {{ .Elapsed }}"></div>{{ end }}</div>
+</div>
+{{- end }}
+</div>
+
+<h2>Logs</h2>
+{{- range .Resources }}
+<details id="{{ .ID }}">
+<summary>{{ .Type }}: {{ .Name }} ({{ .Lines }} lines, {{ bytes .Size }})</summary>
+<pre>{{ .Excerpt }}</pre>
+</details>
+{{- end }}
+
+<script>
+document.querySelectorAll(".bar").forEach(function (bar) {
+  bar.addEventListener("click", function () {
+    var log = document.getElementById(bar.dataset.log);
+    document.querySelectorAll("details.focus").forEach(function (d) { d.classList.remove("focus"); });
+    log.open = true;
+    log.classList.add("focus");
+    log.scrollIntoView({ behavior: "smooth" });
+  });
+});
+</script>
+</body>
+</html>
+`
+
+// htmlReport represents the data used to produce
+// the HTML report for the build summary.
+type htmlReport struct {
+	Org       string
+	Repo      string
+	Start     string
+	Generated string
+	Summary   *Summary
+	Resources []*htmlResource
+	// build the summary was compared against
+	Compared string
+	// note for the comparison against the previous build
+	Comparison string
+}
+
+// htmlResource represents a resource displayed in
+// the HTML report for the build summary.
+type htmlResource struct {
+	*Resource
+
+	// unique identifier for the resource in the report
+	ID string
+	// percentage offset from the start of the timeline
+	Offset string
+	// percentage width of the timeline
+	Width string
+}
+
+// htmlResources is a helper function to calculate the position of
+// each resource on a timeline spanning the entire build.
+func htmlResources(s *Summary) []*htmlResource {
+	logrus.Trace("calculating timeline for HTML report")
+
+	// capture the span of the timeline based off the build timestamps
+	start, end := s.Totals.Started, s.Totals.Finished
+
+	// extend the span of the timeline to include all resources
+	for _, r := range s.Resources() {
+		if r.Started > 0 && (start == 0 || r.Started < start) {
+			start = r.Started
+		}
+
+		if r.Finished > end {
+			end = r.Finished
+		}
+	}
+
+	// ensure the span of the timeline is never empty
+	if end <= start {
+		end = start + 1
+	}
+
+	span := float64(end - start)
+
+	resources := []*htmlResource{}
+
+	for _, r := range s.Resources() {
+		bar := &htmlResource{
+			Resource: r,
+			ID:       fmt.Sprintf("log-%s-%d", r.Type, r.Number),
+		}
+
+		// check if the resource started running
+		if r.Started > 0 {
+			// use the end of the timeline for resources still running
+			finished := r.Finished
+			if finished == 0 {
+				finished = end
+			}
+
+			bar.Offset = humanize.FtoaWithDigits(float64(r.Started-start)/span*100, 2)
+			bar.Width = humanize.FtoaWithDigits(float64(finished-r.Started)/span*100, 2)
+		}
+
+		resources = append(resources, bar)
+	}
+
+	return resources
+}
+
+// htmlOutput is a helper function to output the provided build summary
+// as a self-contained HTML report.
+//
+// The report includes the table of resources in the build, a timeline
+// of when each resource ran and an excerpt of the logs for each resource.
+// The table includes the values from and change from the other build
+// for each resource if the build was compared against another build.
+// No external resources are referenced so the report can be archived.
+func htmlOutput(w io.Writer, s *Summary) error {
+	logrus.Debug("creating HTML report for build summary")
+
+	// parse the template for the report
+	//
+	// https://pkg.go.dev/html/template#Template.Parse
+	tmpl, err := template.New("html").Funcs(template.FuncMap{
+		"bytes": humanize.Bytes,
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+
+	// create the data for the report
+	report := &htmlReport{
+		Org:       s.Build.GetRepo().GetOrg(),
+		Repo:      s.Build.GetRepo().GetName(),
+		Generated: time.Now().UTC().Format(time.RFC3339),
+		Summary:   s,
+		Resources: htmlResources(s),
+	}
+
+	// check if the build started running
+	if s.Totals.Started > 0 {
+		report.Start = time.Unix(s.Totals.Started, 0).UTC().Format(time.RFC3339)
+	}
+
+	// check if the build was compared against a previous build
+	if s.Previous != nil {
+		report.Compared = compareBuild(s)
+		report.Comparison = compareNote(s)
+	}
+
+	return tmpl.Execute(w, report)
+}
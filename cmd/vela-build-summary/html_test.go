@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/go-vela/server/api/types"
+	"github.com/go-vela/server/constants"
+)
+
+func TestHTMLOutput_Compare(t *testing.T) {
+	// setup types
+	repo := new(api.Repo)
+	repo.SetOrg("foo")
+	repo.SetName("bar")
+
+	build := new(api.Build)
+	build.SetNumber(2)
+	build.SetRepo(repo)
+
+	prev := new(api.Build)
+	prev.SetNumber(1)
+	prev.SetRepo(repo)
+
+	head := testSummary(2, testStep("build", constants.StatusSuccess, 2*time.Minute), testStep("lint", constants.StatusSuccess, time.Minute))
+	head.Build = build
+
+	base := testSummary(1, testStep("build", constants.StatusSuccess, time.Minute))
+	base.Build = prev
+
+	head.compare(base, 10)
+
+	buf := new(bytes.Buffer)
+
+	err := htmlOutput(buf, head)
+	if err != nil {
+		t.Fatalf("htmlOutput returned err: %v", err)
+	}
+
+	for _, want := range []string{
+		"<th>Base Status</th><th>Base Duration</th><th>Base Log Lines</th><th>Base Log Size</th><th>Δ Duration</th>",
+		`<td><span class="badge status-success">success</span></td><td class="num">1m0s</td><td class="num">0</td><td class="num">0 B</td><td class="num">&#43;1m0s ▲</td>`,
+		`<td>-</td><td class="num">-</td><td class="num">-</td><td class="num">-</td><td class="num">new</td>`,
+		`<td class="num">new</td>`,
+		"<strong>Compared to build #1</strong>: regressed build (duration), added lint",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("htmlOutput is missing %q", want)
+		}
+	}
+}
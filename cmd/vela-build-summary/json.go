@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jsonVersion represents the version of the JSON document produced
+// for the build summary. This should be incremented any time a
+// breaking change is made to the structure of the document.
+const jsonVersion = "v1"
+
+// jsonDocument represents the JSON document produced for the build summary.
+type jsonDocument struct {
+	Version     string           `json:"version"`
+	Org         string           `json:"org"`
+	Repo        string           `json:"repo"`
+	Services    []*jsonResource  `json:"services"`
+	Steps       []*jsonResource  `json:"steps"`
+	Build       *jsonResource    `json:"build"`
+	Excluded    int              `json:"excluded"`
+	Critical    []string         `json:"critical_path"`
+	Breakdown   *jsonBreakdown   `json:"breakdown"`
+	Utilization *jsonUtilization `json:"utilization"`
+	Previous    *jsonBuild       `json:"previous_build,omitempty"`
+	Removed     []*jsonResource  `json:"removed,omitempty"`
+}
+
+// jsonBuild represents the build the build summary
+// was compared against in the JSON document.
+type jsonBuild struct {
+	Org    string `json:"org"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+}
+
+// jsonDelta represents the change for a resource from
+// the previous build in the JSON document.
+type jsonDelta struct {
+	Duration    int64    `json:"duration_seconds"`
+	LogLines    int      `json:"log_lines"`
+	LogBytes    int64    `json:"log_bytes"`
+	Status      string   `json:"previous_status"`
+	Regressions []string `json:"regressions"`
+}
+
+// jsonBreakdown represents the breakdown of where the time for
+// the build was spent in the JSON document.
+type jsonBreakdown struct {
+	Pending int64 `json:"pending_seconds"`
+	Queued  int64 `json:"queued_seconds"`
+	Idle    int64 `json:"idle_seconds"`
+	Running int64 `json:"running_seconds"`
+}
+
+// jsonUtilization represents the parallelism and utilization
+// of the build in the JSON document.
+type jsonUtilization struct {
+	Peak      int     `json:"peak_concurrency"`
+	Average   float64 `json:"average_concurrency"`
+	StepTime  int64   `json:"step_seconds"`
+	WallClock int64   `json:"wall_clock_seconds"`
+	Idle      float64 `json:"idle_fraction"`
+}
+
+// jsonResource represents a resource in the JSON document
+// produced for the build summary.
+type jsonResource struct {
+	Type      string     `json:"type"`
+	Name      string     `json:"name"`
+	Number    int        `json:"number"`
+	Status    string     `json:"status"`
+	Stage     string     `json:"stage"`
+	Queued    int64      `json:"queued_seconds"`
+	Duration  int64      `json:"duration_seconds"`
+	LogLines  int        `json:"log_lines"`
+	LogBytes  uint64     `json:"log_bytes"`
+	LogErrors int        `json:"log_errors"`
+	LogRate   int64      `json:"log_rate_bytes_per_second"`
+	Complete  bool       `json:"complete"`
+	Critical  bool       `json:"critical"`
+	Slack     int64      `json:"slack_seconds"`
+	Delta     *jsonDelta `json:"delta,omitempty"`
+}
+
+// newJSONResource is a helper function to convert a
+// resource into a resource for the JSON document.
+//
+// The rate is only included for complete resources, since
+// the rate for other resources is based off partial logs.
+func newJSONResource(r *Resource) *jsonResource {
+	resource := &jsonResource{
+		Type:      r.Type,
+		Name:      r.Name,
+		Number:    r.Number,
+		Status:    r.Status,
+		Stage:     r.Stage,
+		Queued:    int64(r.Queued().Seconds()),
+		Duration:  int64(r.Duration.Seconds()),
+		LogLines:  r.Lines,
+		LogBytes:  r.Size,
+		LogErrors: r.Errors,
+		Complete:  r.Complete(),
+		Critical:  r.Critical,
+		Slack:     int64(r.Slack.Seconds()),
+	}
+
+	// check if the resource is complete
+	if r.Complete() {
+		resource.LogRate = r.Rate
+	}
+
+	// check if the resource ran in the previous build
+	if r.Previous != nil {
+		resource.Delta = &jsonDelta{
+			Duration:    int64((r.Duration - r.Previous.Duration).Seconds()),
+			LogLines:    r.Lines - r.Previous.Lines,
+			LogBytes:    int64(r.Size) - int64(r.Previous.Size), //nolint:gosec // log sizes don't overflow an int64
+			Status:      r.Previous.Status,
+			Regressions: append([]string{}, r.Regressions...),
+		}
+	}
+
+	return resource
+}
+
+// jsonOutput is a helper function to output the provided build summary
+// as a versioned JSON document.
+//
+// The document includes the same information displayed in the table,
+// but with raw values rather than humanized ones so downstream tools
+// are able to consume the build summary.
+func jsonOutput(w io.Writer, s *Summary) error {
+	logrus.Debug("creating JSON document for build summary")
+
+	// create the document with the build totals
+	doc := &jsonDocument{
+		Version:  jsonVersion,
+		Org:      s.Build.GetRepo().GetOrg(),
+		Repo:     s.Build.GetRepo().GetName(),
+		Services: []*jsonResource{},
+		Steps:    []*jsonResource{},
+		Build:    newJSONResource(s.Totals),
+		Excluded: s.Excluded,
+		Critical: criticalNames(s),
+		Breakdown: &jsonBreakdown{
+			Pending: int64(s.Breakdown.Pending.Seconds()),
+			Queued:  int64(s.Breakdown.Queued.Seconds()),
+			Idle:    int64(s.Breakdown.Idle.Seconds()),
+			Running: int64(s.Breakdown.Running.Seconds()),
+		},
+		Utilization: &jsonUtilization{
+			Peak:      s.Utilization.Peak,
+			Average:   s.Utilization.Average,
+			StepTime:  int64(s.Utilization.StepTime.Seconds()),
+			WallClock: int64(s.Utilization.WallClock.Seconds()),
+			Idle:      s.Utilization.Idle,
+		},
+	}
+
+	logrus.Trace("adding services to JSON document")
+	// add the services to the document
+	for _, r := range s.Services {
+		doc.Services = append(doc.Services, newJSONResource(r))
+	}
+
+	logrus.Trace("adding steps to JSON document")
+	// add the steps to the document
+	for _, r := range s.Steps {
+		doc.Steps = append(doc.Steps, newJSONResource(r))
+	}
+
+	// check if the build was compared against a previous build
+	if s.Previous != nil {
+		logrus.Trace("adding comparison to JSON document")
+
+		doc.Previous = &jsonBuild{
+			Org:    s.Previous.Build.GetRepo().GetOrg(),
+			Repo:   s.Previous.Build.GetRepo().GetName(),
+			Number: s.Previous.Totals.Number,
+		}
+
+		// add the services and steps removed from the build to the document
+		for _, r := range s.Removed {
+			doc.Removed = append(doc.Removed, newJSONResource(r))
+		}
+	}
+
+	// create a new encoder for the document
+	//
+	// https://pkg.go.dev/encoding/json#NewEncoder
+	enc := json.NewEncoder(w)
+
+	// serialize the document as pretty JSON
+	//
+	// https://pkg.go.dev/encoding/json#Encoder.SetIndent
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
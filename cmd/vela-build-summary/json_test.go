@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	api "github.com/go-vela/server/api/types"
+	"github.com/go-vela/server/constants"
+)
+
+// testBuildSummary is a helper function to produce the build summary for a
+// build with a service, a step that failed and a step that was skipped.
+func testBuildSummary() *Summary {
+	repo := new(api.Repo)
+	repo.SetOrg("foo")
+	repo.SetName("bar")
+
+	build := new(api.Build)
+	build.SetRepo(repo)
+	build.SetNumber(1)
+	build.SetStatus(constants.StatusFailure)
+	build.SetCreated(1000)
+	build.SetEnqueued(1002)
+	build.SetStarted(1010)
+	build.SetFinished(1070)
+
+	service := new(api.Service)
+	service.SetID(1)
+	service.SetNumber(1)
+	service.SetName("postgres")
+	service.SetStatus(constants.StatusSuccess)
+	service.SetCreated(1000)
+	service.SetStarted(1010)
+	service.SetFinished(1070)
+
+	clone := new(api.Step)
+	clone.SetID(1)
+	clone.SetNumber(1)
+	clone.SetName("clone")
+	clone.SetStage("init")
+	clone.SetStatus(constants.StatusSuccess)
+	clone.SetCreated(1000)
+	clone.SetStarted(1010)
+	clone.SetFinished(1020)
+
+	test := new(api.Step)
+	test.SetID(2)
+	test.SetNumber(2)
+	test.SetName("test")
+	test.SetStage("test")
+	test.SetStatus(constants.StatusFailure)
+	test.SetExitCode(1)
+	test.SetCreated(1000)
+	test.SetStarted(1020)
+	test.SetFinished(1060)
+
+	publish := new(api.Step)
+	publish.SetID(3)
+	publish.SetNumber(3)
+	publish.SetName("publish")
+	publish.SetStage("publish")
+	publish.SetStatus(constants.StatusSkipped)
+	publish.SetCreated(1000)
+
+	// capture the logs for the service and steps
+	index := newLogIndex(minLogMemory)
+
+	_, _ = index.Service(1).Write([]byte("listening on port 5432\n"))
+	_, _ = index.Step(1).Write([]byte("cloning repository\ncloned repository\n"))
+	_, _ = index.Step(2).Write([]byte("running tests\nERROR: test failed\n"))
+
+	// the steps are returned in reverse order from the Vela server
+	return newSummary(build, index, nil, &[]api.Service{*service}, &[]api.Step{*publish, *test, *clone})
+}
+
+func TestJSONOutput(t *testing.T) {
+	// setup types
+	s := testBuildSummary()
+
+	want := `{
+  "version": "v1",
+  "org": "foo",
+  "repo": "bar",
+  "services": [
+    {
+      "type": "service",
+      "name": "postgres",
+      "number": 1,
+      "status": "success",
+      "stage": "",
+      "queued_seconds": 10,
+      "duration_seconds": 60,
+      "log_lines": 1,
+      "log_bytes": 23,
+      "log_errors": 0,
+      "log_rate_bytes_per_second": 0,
+      "complete": true,
+      "critical": false,
+      "slack_seconds": 0
+    }
+  ],
+  "steps": [
+    {
+      "type": "step",
+      "name": "clone",
+      "number": 1,
+      "status": "success",
+      "stage": "init",
+      "queued_seconds": 10,
+      "duration_seconds": 10,
+      "log_lines": 2,
+      "log_bytes": 37,
+      "log_errors": 0,
+      "log_rate_bytes_per_second": 3,
+      "complete": true,
+      "critical": true,
+      "slack_seconds": 0
+    },
+    {
+      "type": "step",
+      "name": "test",
+      "number": 2,
+      "status": "failure",
+      "stage": "test",
+      "queued_seconds": 20,
+      "duration_seconds": 40,
+      "log_lines": 2,
+      "log_bytes": 33,
+      "log_errors": 1,
+      "log_rate_bytes_per_second": 0,
+      "complete": true,
+      "critical": true,
+      "slack_seconds": 0
+    },
+    {
+      "type": "step",
+      "name": "publish",
+      "number": 3,
+      "status": "skipped",
+      "stage": "publish",
+      "queued_seconds": 0,
+      "duration_seconds": 0,
+      "log_lines": 0,
+      "log_bytes": 0,
+      "log_errors": 0,
+      "log_rate_bytes_per_second": 0,
+      "complete": false,
+      "critical": false,
+      "slack_seconds": 0
+    }
+  ],
+  "build": {
+    "type": "build",
+    "name": "",
+    "number": 1,
+    "status": "failure",
+    "stage": "",
+    "queued_seconds": 8,
+    "duration_seconds": 60,
+    "log_lines": 5,
+    "log_bytes": 93,
+    "log_errors": 1,
+    "log_rate_bytes_per_second": 1,
+    "complete": true,
+    "critical": false,
+    "slack_seconds": 0
+  },
+  "excluded": 1,
+  "critical_path": [
+    "clone",
+    "test"
+  ],
+  "breakdown": {
+    "pending_seconds": 2,
+    "queued_seconds": 8,
+    "idle_seconds": 10,
+    "running_seconds": 50
+  },
+  "utilization": {
+    "peak_concurrency": 1,
+    "average_concurrency": 0.8333333333333334,
+    "step_seconds": 50,
+    "wall_clock_seconds": 60,
+    "idle_fraction": 0.16666666666666663
+  }
+}
+`
+
+	buf := new(bytes.Buffer)
+
+	err := jsonOutput(buf, s)
+	if err != nil {
+		t.Fatalf("jsonOutput returned err: %v", err)
+	}
+
+	if buf.String() != want {
+		t.Errorf("jsonOutput is %s, want %s", buf.String(), want)
+	}
+}
+
+func TestJSONOutput_RoundTrip(t *testing.T) {
+	// setup types
+	s := testBuildSummary()
+
+	buf := new(bytes.Buffer)
+
+	err := jsonOutput(buf, s)
+	if err != nil {
+		t.Fatalf("jsonOutput returned err: %v", err)
+	}
+
+	got := new(jsonDocument)
+
+	err = json.Unmarshal(buf.Bytes(), got)
+	if err != nil {
+		t.Fatalf("unable to unmarshal JSON document: %v", err)
+	}
+
+	if got.Version != jsonVersion {
+		t.Errorf("version is %s, want %s", got.Version, jsonVersion)
+	}
+
+	if len(got.Services) != len(s.Services) || len(got.Steps) != len(s.Steps) {
+		t.Fatalf("document has %d services and %d steps, want %d and %d", len(got.Services), len(got.Steps), len(s.Services), len(s.Steps))
+	}
+
+	for i, r := range s.Steps {
+		step := got.Steps[i]
+
+		if step.Name != r.Name || step.Status != r.Status || step.LogLines != r.Lines || step.LogBytes != r.Size || step.Complete != r.Complete() {
+			t.Errorf("step %d is %+v, want %s", i, step, r.Name)
+		}
+	}
+}
+
+func TestJSONOutput_Compare(t *testing.T) {
+	// setup types
+	head := testBuildSummary()
+	head.Build.SetNumber(2)
+	head.Totals.Number = 2
+
+	base := testBuildSummary()
+
+	// the test step passed faster and a lint step ran in the previous build
+	base.Steps[1].Status = constants.StatusSuccess
+	base.Steps[1].Duration = 30 * time.Second
+	base.Steps = append(base.Steps, testStep("lint", constants.StatusSuccess, time.Minute))
+
+	head.compare(base, 10)
+
+	buf := new(bytes.Buffer)
+
+	err := jsonOutput(buf, head)
+	if err != nil {
+		t.Fatalf("jsonOutput returned err: %v", err)
+	}
+
+	got := new(jsonDocument)
+
+	err = json.Unmarshal(buf.Bytes(), got)
+	if err != nil {
+		t.Fatalf("unable to unmarshal JSON document: %v", err)
+	}
+
+	if got.Previous == nil || got.Previous.Number != 1 || got.Previous.Org != "foo" || got.Previous.Repo != "bar" {
+		t.Errorf("previous_build is %+v, want foo/bar #1", got.Previous)
+	}
+
+	delta := got.Steps[1].Delta
+	if delta == nil {
+		t.Fatalf("delta for step %s is missing", got.Steps[1].Name)
+	}
+
+	if delta.Duration != 10 || delta.Status != constants.StatusSuccess {
+		t.Errorf("delta for step %s is %+v, want 10 seconds from success", got.Steps[1].Name, delta)
+	}
+
+	if !slices.Contains(delta.Regressions, "duration") {
+		t.Errorf("regressions for step %s are %v, want duration", got.Steps[1].Name, delta.Regressions)
+	}
+
+	if len(got.Removed) != 1 || got.Removed[0].Name != "lint" {
+		t.Errorf("removed is %+v, want lint", got.Removed)
+	}
+}
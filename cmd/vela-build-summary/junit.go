@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+// junitTestSuites represents the root element of the
+// JUnit XML document produced for the build summary.
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Errors   int               `xml:"errors,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     float64           `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite represents a suite of test cases in the
+// JUnit XML document produced for the build summary.
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       float64          `xml:"time,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Cases      []*junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase represents a test case in the JUnit
+// XML document produced for the build summary.
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       float64          `xml:"time,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitMessage    `xml:"failure,omitempty"`
+	Error      *junitMessage    `xml:"error,omitempty"`
+	Skipped    *junitMessage    `xml:"skipped,omitempty"`
+	SystemOut  *junitData       `xml:"system-out,omitempty"`
+}
+
+// junitProperties represents the properties for a suite
+// or test case in the JUnit XML document.
+type junitProperties struct {
+	Properties []*junitProperty `xml:"property"`
+}
+
+// junitProperty represents a property for a suite
+// or test case in the JUnit XML document.
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitData represents character data for a test
+// case in the JUnit XML document.
+type junitData struct {
+	Data string `xml:",cdata"`
+}
+
+// junitMessage represents a failure, error or skipped element
+// for a test case in the JUnit XML document.
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// junitANSIPattern represents the pattern used to match the ANSI escape
+// sequences for colors and cursor movement commonly found in logs.
+var junitANSIPattern = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+// junitSanitize is a helper function to make the provided logs safe for the
+// JUnit XML document by removing ANSI escape sequences and replacing any
+// characters that aren't allowed in XML 1.0 with the replacement character.
+//
+// The logs are added as character data, which isn't escaped when encoded.
+//
+// https://www.w3.org/TR/xml/#charsets
+func junitSanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return r
+		case r >= 0x20 && r <= 0xD7FF, r >= 0xE000 && r <= 0xFFFD, r >= 0x10000 && r <= utf8.MaxRune:
+			return r
+		default:
+			return utf8.RuneError
+		}
+	}, junitANSIPattern.ReplaceAllString(s, ""))
+}
+
+// junitCompare is a helper function to produce the properties with the
+// values from and change from the previous build for a test case.
+//
+// No properties are produced for resources that didn't run in the previous build.
+func junitCompare(r *Resource) *junitProperties {
+	// check if the resource ran in the previous build
+	if r.Previous == nil {
+		return nil
+	}
+
+	properties := []*junitProperty{
+		{Name: "previous_status", Value: r.Previous.Status},
+		{Name: "previous_duration_seconds", Value: strconv.FormatInt(int64(r.Previous.Duration.Seconds()), 10)},
+		{Name: "previous_log_bytes", Value: strconv.FormatUint(r.Previous.Size, 10)},
+		{Name: "previous_log_lines", Value: strconv.Itoa(r.Previous.Lines)},
+		{Name: "duration_delta_seconds", Value: strconv.FormatInt(int64((r.Duration - r.Previous.Duration).Seconds()), 10)},
+		{Name: "log_bytes_delta", Value: strconv.FormatInt(int64(r.Size)-int64(r.Previous.Size), 10)}, //nolint:gosec // log sizes don't overflow an int64
+		{Name: "log_lines_delta", Value: strconv.Itoa(r.Lines - r.Previous.Lines)},
+	}
+
+	// check if the resource regressed from the previous build
+	if len(r.Regressions) > 0 {
+		properties = append(properties, &junitProperty{Name: "regressions", Value: strings.Join(r.Regressions, ",")})
+	}
+
+	return &junitProperties{Properties: properties}
+}
+
+// junitTestCases is a helper function to convert the provided
+// resources into a suite of test cases for the JUnit XML document.
+func junitTestCases(name string, resources []*Resource) *junitTestSuite {
+	logrus.Tracef("adding %s to JUnit XML document", name)
+
+	suite := &junitTestSuite{
+		Name: name,
+	}
+
+	// iterate through all resources in the list
+	for _, r := range resources {
+		tc := &junitTestCase{
+			Name:       r.Name,
+			ClassName:  r.Stage,
+			Time:       r.Duration.Seconds(),
+			Properties: junitCompare(r),
+		}
+
+		// attach the excerpt of logs for the resource
+		if len(r.Excerpt) > 0 {
+			tc.SystemOut = &junitData{Data: junitSanitize(r.Excerpt)}
+		}
+
+		// use the suite name for resources without a stage
+		if len(tc.ClassName) == 0 {
+			tc.ClassName = name
+		}
+
+		switch {
+		// check if the resource errored
+		case r.Status == constants.StatusError || r.Status == constants.StatusKilled || len(r.Error) > 0:
+			// use the status for resources without an error message
+			msg := r.Error
+			if len(msg) == 0 {
+				msg = r.Status
+			}
+
+			tc.Error = &junitMessage{Message: msg, Type: r.Status}
+
+			suite.Errors++
+		// check if the resource failed
+		case r.Status == constants.StatusFailure || r.ExitCode != 0:
+			tc.Failure = &junitMessage{Message: fmt.Sprintf("exit code %d", r.ExitCode), Type: r.Status}
+
+			suite.Failures++
+		// check if the resource never ran
+		case r.Status == constants.StatusSkipped || r.Status == constants.StatusCanceled:
+			tc.Skipped = &junitMessage{Message: r.Status}
+
+			suite.Skipped++
+		}
+
+		suite.Tests++
+		suite.Time += tc.Time
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	return suite
+}
+
+// junitOutput is a helper function to output the provided build summary
+// as a JUnit XML document.
+//
+// Each service and step in the build is treated as a test case, with the
+// stage as the class name and the duration as the time. The status, exit
+// code and error for the resource determine if the test case failed, and
+// an excerpt of the logs for the resource is attached as system-out.
+//
+// If the build was compared against a previous build, the values from and
+// change from the previous build are added as properties for each test
+// case. Regressions don't fail a test case, since the resource still ran.
+func junitOutput(w io.Writer, s *Summary) error {
+	logrus.Debug("creating JUnit XML document for build summary")
+
+	// create the document with the suites for the build
+	doc := &junitTestSuites{
+		Name: fmt.Sprintf("%s #%d", s.Build.GetRepo().GetFullName(), s.Totals.Number),
+		Time: s.Totals.Duration.Seconds(),
+		Suites: []*junitTestSuite{
+			junitTestCases("services", s.Services),
+			junitTestCases("steps", s.Steps),
+		},
+	}
+
+	// update the totals for the document with each suite
+	for _, suite := range doc.Suites {
+		// check if the build was compared against a previous build
+		if s.Previous != nil {
+			suite.Properties = &junitProperties{
+				Properties: []*junitProperty{{Name: "previous_build", Value: compareBuild(s)}},
+			}
+		}
+
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		doc.Skipped += suite.Skipped
+	}
+
+	// add the XML header to the document
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	// create a new encoder for the document
+	//
+	// https://pkg.go.dev/encoding/xml#NewEncoder
+	enc := xml.NewEncoder(w)
+
+	// serialize the document as pretty XML
+	//
+	// https://pkg.go.dev/encoding/xml#Encoder.Indent
+	enc.Indent("", "  ")
+
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	// add a trailing newline to the document
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
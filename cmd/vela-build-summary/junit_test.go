@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	api "github.com/go-vela/server/api/types"
+	"github.com/go-vela/server/constants"
+)
+
+func TestJUnitSanitize(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "plain",
+			data: "foo\n\tbar\r\n",
+			want: "foo\n\tbar\r\n",
+		},
+		{
+			name: "colors",
+			data: "\x1b[31mERROR\x1b[0m: foo \x1b[1;32mok\x1b[m",
+			want: "ERROR: foo ok",
+		},
+		{
+			name: "cursor movement",
+			data: "\x1b[2K\x1b[1Gprogress\x1b[?25l",
+			want: "progress",
+		},
+		{
+			name: "operating system command",
+			data: "\x1b]0;title\x07foo\x1b]8;;https://go-vela.github.io\x1b\\link",
+			want: "foolink",
+		},
+		{
+			name: "single character escape",
+			data: "\x1bMfoo\x1b=",
+			want: "foo�=",
+		},
+		{
+			name: "control characters",
+			data: "foo\x00bar\x07baz\x08\x7f",
+			want: "foo�bar�baz�\x7f",
+		},
+		{
+			name: "invalid utf-8",
+			data: "foo\xffbar",
+			want: "foo�bar",
+		},
+		{
+			name: "unicode",
+			data: "✅ done 🎉",
+			want: "✅ done 🎉",
+		},
+		{
+			name: "noncharacters",
+			data: "foo￾bar￿",
+			want: "foo�bar�",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := junitSanitize(test.data)
+
+			if got != test.want {
+				t.Errorf("junitSanitize is %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJUnitOutput(t *testing.T) {
+	// setup types
+	repo := new(api.Repo)
+	repo.SetFullName("foo/bar")
+
+	build := new(api.Build)
+	build.SetNumber(1)
+	build.SetRepo(repo)
+
+	s := &Summary{
+		Build:  build,
+		Totals: &Resource{Type: "build", Number: 1, Status: constants.StatusFailure, Duration: time.Minute},
+		Services: []*Resource{
+			{Type: "service", Name: "redis", Number: 1, Status: constants.StatusSuccess, Excerpt: "ready\x00"},
+		},
+		Steps: []*Resource{
+			{Type: "step", Name: "test", Number: 1, Status: constants.StatusFailure, Stage: "test", ExitCode: 1, Excerpt: "\x1b[31mERROR\x1b[0m: foo ]]> bar\x1b"},
+			{Type: "step", Name: "lint", Number: 2, Status: constants.StatusError, Error: "bad\x1bthing"},
+			{Type: "step", Name: "docs", Number: 3, Status: constants.StatusSkipped},
+			{Type: "step", Name: "build", Number: 4, Status: constants.StatusSuccess, Excerpt: "done"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+
+	err := junitOutput(buf, s)
+	if err != nil {
+		t.Fatalf("junitOutput returned err: %v", err)
+	}
+
+	// decode every token in the document
+	//
+	// https://pkg.go.dev/encoding/xml#Decoder.Token
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+
+	for {
+		_, err = dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("unable to decode JUnit XML document: %v\n%s", err, buf.String())
+		}
+	}
+
+	// decode the document
+	doc := new(junitTestSuites)
+
+	err = xml.Unmarshal(buf.Bytes(), doc)
+	if err != nil {
+		t.Fatalf("unable to unmarshal JUnit XML document: %v", err)
+	}
+
+	if doc.Name != "foo/bar #1" {
+		t.Errorf("Name is %s, want foo/bar #1", doc.Name)
+	}
+
+	if doc.Tests != 5 || doc.Failures != 1 || doc.Errors != 1 || doc.Skipped != 1 {
+		t.Errorf("totals are %d tests, %d failures, %d errors and %d skipped, want 5, 1, 1 and 1",
+			doc.Tests, doc.Failures, doc.Errors, doc.Skipped)
+	}
+
+	steps := doc.Suites[1]
+
+	if got, want := steps.Cases[0].SystemOut.Data, "ERROR: foo ]]> bar�"; got != want {
+		t.Errorf("system-out is %q, want %q", got, want)
+	}
+
+	if got, want := steps.Cases[0].ClassName, "test"; got != want {
+		t.Errorf("classname is %s, want %s", got, want)
+	}
+
+	if got, want := steps.Cases[1].Error.Message, "bad�thing"; got != want {
+		t.Errorf("error message is %q, want %q", got, want)
+	}
+
+	if got, want := doc.Suites[0].Cases[0].SystemOut.Data, "ready�"; got != want {
+		t.Errorf("system-out is %q, want %q", got, want)
+	}
+}
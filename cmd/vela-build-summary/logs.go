@@ -0,0 +1,494 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/sdk-go/vela"
+)
+
+// excerptLines represents the maximum number of lines
+// of logs captured in the excerpt for a resource.
+const excerptLines = 50
+
+// excerptSize represents the maximum number of bytes
+// of logs captured in the excerpt for a resource.
+//
+// The excerpt is kept after the logs are analyzed, so this
+// bounds the memory used for builds with many resources.
+const excerptSize = 32 * 1024
+
+// minLogMemory represents the minimum amount of memory in
+// bytes used for analyzing the logs of a single resource.
+const minLogMemory = 4096
+
+// logErrorPattern represents the pattern used to
+// count the lines of logs that report an error.
+var logErrorPattern = regexp.MustCompile(`(?i)\b(error|fatal|panic)\b`)
+
+// logStats represents the metrics captured for the
+// logs of a single service or step.
+//
+// The logs are analyzed as they're written, so only the
+// line being read and the last lines for the excerpt are
+// kept in memory, bounded by the provided limit.
+type logStats struct {
+	// lines of logs the resource produced
+	Lines int
+	// size of logs the resource produced in bytes
+	Size uint64
+	// lines of logs the resource produced reporting an error
+	Errors int
+
+	// maximum number of bytes kept in memory for the logs
+	limit int
+	// line of logs currently being read
+	line []byte
+	// last lines of logs read for the excerpt
+	tail [][]byte
+	// number of bytes kept in memory for the excerpt
+	tailSize int
+	// ID of the last log entry analyzed
+	entry int64
+}
+
+// newLogStats creates the metrics for the logs of a
+// resource, keeping at most limit bytes in memory.
+func newLogStats(limit int) *logStats {
+	return &logStats{limit: max(limit, minLogMemory)}
+}
+
+// Write analyzes the provided log data.
+//
+// https://pkg.go.dev/io#Writer
+func (l *logStats) Write(p []byte) (int, error) {
+	l.Size += uint64(len(p))
+
+	for data := p; len(data) > 0; {
+		// find the end of the line being read
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			l.buffer(data)
+
+			break
+		}
+
+		l.buffer(data[:i])
+		l.Lines++
+		l.flush()
+
+		data = data[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Close analyzes the last line of logs when it doesn't end with a newline.
+func (l *logStats) Close() error {
+	// check if there is a line being read
+	if len(l.line) > 0 {
+		l.flush()
+	}
+
+	// release the buffer for the line being read
+	l.line = nil
+
+	return nil
+}
+
+// Excerpt returns the last lines of logs the resource produced.
+func (l *logStats) Excerpt() string {
+	return strings.TrimRight(string(bytes.Join(l.tail, []byte("\n"))), "\n")
+}
+
+// buffer is a helper function to add the provided data to
+// the line being read, truncating lines too long to keep.
+func (l *logStats) buffer(data []byte) {
+	// the line being read is limited to half of the memory
+	// so the other half is available for the excerpt
+	room := l.limit/2 - len(l.line)
+	if room <= 0 {
+		return
+	}
+
+	l.line = append(l.line, data[:min(len(data), room)]...)
+}
+
+// flush is a helper function to analyze the line being read
+// and move it into the excerpt, dropping the oldest lines in
+// the excerpt to stay within the memory limit.
+func (l *logStats) flush() {
+	// check if the line reports an error
+	if logErrorPattern.Match(l.line) {
+		l.Errors++
+	}
+
+	// create a variable to track the line for the excerpt
+	var line []byte
+
+	// check if the excerpt is full, so the buffer
+	// for the oldest line is reused for this line
+	if len(l.tail) == excerptLines {
+		line = l.tail[0][:0]
+
+		l.tailSize -= len(l.tail[0])
+		l.tail[0] = nil
+		l.tail = l.tail[1:]
+	}
+
+	// copy the line since the buffer is reused, truncating
+	// lines too long to keep in the excerpt
+	line = append(line, l.line[:min(len(l.line), excerptSize)]...)
+	l.line = l.line[:0]
+
+	l.tail = append(l.tail, line)
+	l.tailSize += len(line)
+
+	// drop the oldest lines until the excerpt fits
+	for len(l.tail) > excerptLines || (l.tailSize > min(l.limit/2, excerptSize) && len(l.tail) > 1) {
+		l.tailSize -= len(l.tail[0])
+		l.tail[0] = nil
+		l.tail = l.tail[1:]
+	}
+}
+
+// logDecoder represents a writer that extracts the base64 encoded
+// data from a page of logs returned by the Vela server.
+//
+// The page is decoded as it's streamed from the server, so the data
+// doesn't need to be loaded in memory at once. The data for each log
+// entry is sent to the metrics for the service or step in the index
+// it belongs to, based off the IDs preceding the data in the entry.
+type logDecoder struct {
+	// index to send the decoded data to
+	index *logIndex
+	// depth of the arrays and objects being read
+	depth int
+	// kind of string being read
+	str int
+	// whether the last character in the string was an escape
+	escaped bool
+	// whether a key is expected next in the log entry
+	expectKey bool
+	// key for the value being read in the log entry
+	key []byte
+	// ID being read for the value in the log entry
+	number *int64
+	// ID of the log entry being read
+	id int64
+	// ID of the service for the log entry being read
+	service int64
+	// ID of the step for the log entry being read
+	step int64
+	// metrics to send the data for the log entry to
+	out *logStats
+	// base64 encoded characters waiting to be decoded
+	pending []byte
+	// buffer for the decoded data
+	decoded []byte
+}
+
+// logKeySize represents the maximum number of characters
+// kept for a key in a log entry, which is enough for
+// every key used to route the data for the entry.
+const logKeySize = 16
+
+// kinds of strings read by the decoder.
+const (
+	// not reading a string
+	stringNone = iota
+	// reading a key in a log entry
+	stringKey
+	// reading a value that isn't analyzed
+	stringValue
+	// reading the data for a log entry
+	stringData
+)
+
+// newLogDecoder creates a decoder sending the data to the provided index.
+func newLogDecoder(index *logIndex) *logDecoder {
+	return &logDecoder{
+		index:   index,
+		key:     make([]byte, 0, logKeySize),
+		pending: make([]byte, 0, 4096),
+		decoded: make([]byte, base64.StdEncoding.DecodedLen(4096)),
+	}
+}
+
+// Write decodes the data from the provided part of the page of logs.
+//
+// https://pkg.go.dev/io#Writer
+func (d *logDecoder) Write(p []byte) (int, error) {
+	for _, c := range p {
+		// check if a string is being read
+		if d.str != stringNone {
+			err := d.read(c)
+			if err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		switch c {
+		case '"':
+			d.open()
+		case '{', '[':
+			d.depth++
+
+			// check if a log entry started
+			if c == '{' && d.depth == 2 {
+				d.start()
+			}
+		case '}', ']':
+			d.depth--
+		case ':':
+			// check if the value for a key in the log entry started
+			if d.depth == 2 {
+				d.expectKey = false
+				d.number = d.field()
+			}
+		case ',':
+			// check if the next key in the log entry started
+			if d.depth == 2 {
+				d.expectKey = true
+				d.number = nil
+			}
+		default:
+			// check if an ID is being read for the log entry
+			if d.depth == 2 && d.number != nil && c >= '0' && c <= '9' {
+				*d.number = *d.number*10 + int64(c-'0')
+			}
+		}
+	}
+
+	return len(p), nil
+}
+
+// start is a helper function to reset the decoder for a new log entry.
+func (d *logDecoder) start() {
+	d.expectKey = true
+	d.key = d.key[:0]
+	d.number = nil
+	d.id, d.service, d.step = 0, 0, 0
+	d.out = nil
+}
+
+// open is a helper function to start reading a string
+// based off where the string is in the page of logs.
+func (d *logDecoder) open() {
+	switch {
+	case d.depth == 2 && d.expectKey:
+		d.str = stringKey
+		d.key = d.key[:0]
+	case d.depth == 2 && string(d.key) == "data":
+		d.str = stringData
+		d.out = d.target()
+	default:
+		d.str = stringValue
+	}
+}
+
+// read is a helper function to read a character in a string.
+func (d *logDecoder) read(c byte) error {
+	switch {
+	case d.escaped:
+		// keep the escaped character, such as for an escaped slash
+		d.escaped = false
+	case c == '\\':
+		d.escaped = true
+
+		return nil
+	case c == '"':
+		kind := d.str
+		d.str = stringNone
+
+		// check if the data for the log entry ended
+		if kind == stringData {
+			return d.decode()
+		}
+
+		return nil
+	}
+
+	switch d.str {
+	case stringKey:
+		// longer keys aren't used to route the data for the entry
+		if len(d.key) < logKeySize {
+			d.key = append(d.key, c)
+		}
+	case stringData:
+		d.pending = append(d.pending, c)
+
+		// check if the pending characters should be decoded
+		if len(d.pending) == cap(d.pending) {
+			return d.decode()
+		}
+	}
+
+	return nil
+}
+
+// field is a helper function to capture the ID set by
+// the value for the key being read in the log entry.
+//
+// A nil ID is returned for keys that don't route the data.
+func (d *logDecoder) field() *int64 {
+	switch string(d.key) {
+	case "id":
+		return &d.id
+	case "service_id":
+		return &d.service
+	case "step_id":
+		return &d.step
+	default:
+		return nil
+	}
+}
+
+// target is a helper function to capture the metrics for
+// the service or step the log entry being read belongs to.
+//
+// The Vela server lists the logs for a build grouped by service and
+// step, so the entries for a resource are analyzed in the order of
+// their IDs. A nil metrics is returned for resources not in the index.
+func (d *logDecoder) target() *logStats {
+	var stats *logStats
+
+	switch {
+	case d.service > 0:
+		stats = d.index.services[d.service]
+	case d.step > 0:
+		stats = d.index.steps[d.step]
+	}
+
+	// check if the logs for the resource are analyzed
+	if stats == nil {
+		logrus.Tracef("skipping log entry %d for a resource that isn't analyzed", d.id)
+
+		return nil
+	}
+
+	// check if the log entry was listed out of order
+	if d.id < stats.entry {
+		logrus.Warnf("log entry %d was listed after log entry %d for the same resource, the excerpt may be out of order", d.id, stats.entry)
+	}
+
+	stats.entry = max(stats.entry, d.id)
+
+	return stats
+}
+
+// decode is a helper function to decode the pending
+// characters and send the data to the metrics.
+func (d *logDecoder) decode() error {
+	// check if the data is sent to any metrics
+	if d.out == nil {
+		d.pending = d.pending[:0]
+
+		return nil
+	}
+
+	n, err := base64.StdEncoding.Decode(d.decoded, d.pending)
+	if err != nil {
+		return fmt.Errorf("unable to decode log data: %w", err)
+	}
+
+	d.pending = d.pending[:0]
+
+	_, err = d.out.Write(d.decoded[:n])
+
+	return err
+}
+
+// logIndex represents the metrics for the logs
+// of a build indexed by the resource that produced them.
+type logIndex struct {
+	// maximum number of bytes kept in memory for each resource
+	limit int
+	// log metrics for each service by ID
+	services map[int64]*logStats
+	// log metrics for each step by ID
+	steps map[int64]*logStats
+}
+
+// newLogIndex creates an index for the log metrics, keeping
+// at most limit bytes in memory for each resource.
+func newLogIndex(limit int) *logIndex {
+	logrus.Tracef("indexing logs with %d bytes of memory for each resource", limit)
+
+	return &logIndex{
+		limit:    limit,
+		services: make(map[int64]*logStats),
+		steps:    make(map[int64]*logStats),
+	}
+}
+
+// Service returns the log metrics for the service with the provided ID.
+func (i *logIndex) Service(id int64) *logStats {
+	// check if the service has log metrics
+	stats, ok := i.services[id]
+	if !ok {
+		stats = newLogStats(i.limit)
+		i.services[id] = stats
+	}
+
+	return stats
+}
+
+// Step returns the log metrics for the step with the provided ID.
+func (i *logIndex) Step(id int64) *logStats {
+	// check if the step has log metrics
+	stats, ok := i.steps[id]
+	if !ok {
+		stats = newLogStats(i.limit)
+		i.steps[id] = stats
+	}
+
+	return stats
+}
+
+// Close analyzes the last line of logs for each resource in the index.
+func (i *logIndex) Close() error {
+	for _, stats := range i.services {
+		_ = stats.Close()
+	}
+
+	for _, stats := range i.steps {
+		_ = stats.Close()
+	}
+
+	return nil
+}
+
+// logStream is a helper function to stream a page of logs from
+// the provided path on the Vela server into the provided index.
+//
+// The Vela SDK decodes the entire page in memory, so the request is sent
+// with the client directly to decode the page as it's streamed instead.
+//
+// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#BuildService.GetLogs
+func logStream(client *vela.Client, path string, index *logIndex) (*vela.Response, error) {
+	logrus.Tracef("streaming logs from %s", path)
+
+	// create the request for the logs
+	//
+	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#Client.NewRequest
+	req, err := client.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// send the request and decode the logs from the response
+	//
+	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#Client.Do
+	return client.Do(req, newLogDecoder(index))
+}
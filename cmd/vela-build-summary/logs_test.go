@@ -0,0 +1,622 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/go-vela/sdk-go/vela"
+	api "github.com/go-vela/server/api/types"
+)
+
+// testLog is a helper function to produce a log entry returned by the
+// Vela server for the step with the provided data base64 encoded.
+func testLog(id, step int64, data string) string {
+	return fmt.Sprintf(`{"id":%d,"build_id":1,"repo_id":1,"step_id":%d,"data":"%s","created_at":1}`, id, step, base64.StdEncoding.EncodeToString([]byte(data)))
+}
+
+// testPage is a helper function to produce a page
+// of logs returned by the Vela server with the
+// provided log entries.
+func testPage(entries ...string) string {
+	return "[" + strings.Join(entries, ",") + "]"
+}
+
+// testLines is a helper function to produce count lines of logs
+// with each line numbered and padded to the provided width.
+func testLines(count, width int) string {
+	b := new(strings.Builder)
+
+	for i := range count {
+		fmt.Fprintf(b, "%-*d\n", width-1, i)
+	}
+
+	return b.String()
+}
+
+func TestLogStats_Write(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		limit   int
+		writes  []string
+		lines   int
+		errors  int
+		excerpt string
+	}{
+		{
+			name:    "single line",
+			limit:   minLogMemory,
+			writes:  []string{"hello\n"},
+			lines:   1,
+			excerpt: "hello",
+		},
+		{
+			name:    "line split across writes",
+			limit:   minLogMemory,
+			writes:  []string{"hel", "lo\nwor", "ld\n"},
+			lines:   2,
+			excerpt: "hello\nworld",
+		},
+		{
+			name:    "final line without newline",
+			limit:   minLogMemory,
+			writes:  []string{"foo\nerror: bar"},
+			lines:   1,
+			errors:  1,
+			excerpt: "foo\nerror: bar",
+		},
+		{
+			name:    "errors",
+			limit:   minLogMemory,
+			writes:  []string{"ERROR: foo\nfatal: bar\npanicked\nterrors\nPanic!\n"},
+			lines:   5,
+			errors:  3,
+			excerpt: "ERROR: foo\nfatal: bar\npanicked\nterrors\nPanic!",
+		},
+		{
+			name:    "line truncated at half of limit",
+			limit:   minLogMemory,
+			writes:  []string{strings.Repeat("a", 5000) + "\n"},
+			lines:   1,
+			excerpt: strings.Repeat("a", minLogMemory/2),
+		},
+		{
+			name:    "line truncated across writes",
+			limit:   minLogMemory,
+			writes:  []string{strings.Repeat("a", 1500), strings.Repeat("b", 1500) + "\n"},
+			lines:   1,
+			excerpt: strings.Repeat("a", 1500) + strings.Repeat("b", minLogMemory/2-1500),
+		},
+		{
+			name:    "line truncated at excerpt size",
+			limit:   1 << 20,
+			writes:  []string{strings.Repeat("a", excerptSize+100) + "\n"},
+			lines:   1,
+			excerpt: strings.Repeat("a", excerptSize),
+		},
+		{
+			name:    "excerpt truncated at half of limit",
+			limit:   minLogMemory,
+			writes:  []string{testLines(10, 500)},
+			lines:   10,
+			excerpt: strings.TrimSuffix(testLines(10, 500)[6*500:], "\n"),
+		},
+		{
+			name:    "excerpt truncated at excerpt lines",
+			limit:   1 << 20,
+			writes:  []string{testLines(60, 10)},
+			lines:   60,
+			excerpt: strings.TrimSuffix(testLines(60, 10)[10*10:], "\n"),
+		},
+		{
+			name:    "limit below minimum",
+			limit:   10,
+			writes:  []string{strings.Repeat("a", 100) + "\n"},
+			lines:   1,
+			excerpt: strings.Repeat("a", 100),
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats := newLogStats(test.limit)
+
+			var size uint64
+
+			for _, w := range test.writes {
+				n, err := stats.Write([]byte(w))
+				if err != nil {
+					t.Errorf("Write returned err: %v", err)
+				}
+
+				if n != len(w) {
+					t.Errorf("Write returned %d, want %d", n, len(w))
+				}
+
+				size += uint64(len(w))
+			}
+
+			err := stats.Close()
+			if err != nil {
+				t.Errorf("Close returned err: %v", err)
+			}
+
+			if stats.Lines != test.lines {
+				t.Errorf("Lines is %d, want %d", stats.Lines, test.lines)
+			}
+
+			if stats.Size != size {
+				t.Errorf("Size is %d, want %d", stats.Size, size)
+			}
+
+			if stats.Errors != test.errors {
+				t.Errorf("Errors is %d, want %d", stats.Errors, test.errors)
+			}
+
+			if got := stats.Excerpt(); got != test.excerpt {
+				t.Errorf("Excerpt is %q, want %q", got, test.excerpt)
+			}
+
+			if stats.line != nil {
+				t.Errorf("Close didn't release the line buffer")
+			}
+		})
+	}
+}
+
+func TestLogDecoder_Write(t *testing.T) {
+	// setup types
+	encode := func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	}
+
+	// setup tests
+	tests := []struct {
+		name    string
+		page    string
+		step    string
+		service string
+	}{
+		{
+			name: "data",
+			page: testPage(testLog(1, 2, "foo\nbar\n")),
+			step: "foo\nbar\n",
+		},
+		{
+			name: "data with whitespace",
+			page: fmt.Sprintf("[\n  { \"id\": 1, \"step_id\" : 2, \"data\" : \"%s\" }\n]", encode("foo\n")),
+			step: "foo\n",
+		},
+		{
+			name: "data key in a value",
+			page: fmt.Sprintf(`[{"id":1,"step_id":2,"name":"data","data":"%s"}]`, encode("foo\n")),
+			step: "foo\n",
+		},
+		{
+			name: "escaped quote in a value",
+			page: fmt.Sprintf(`[{"id":1,"step_id":2,"name":"\",\"data\":\"","data":"%s"}]`, encode("foo\n")),
+			step: "foo\n",
+		},
+		{
+			name: "nested values",
+			page: fmt.Sprintf(`[{"id":1,"step_id":2,"meta":{"step_id":3,"data":"%s"},"tags":[4,"data"],"data":"%s"}]`, encode("bar\n"), encode("foo\n")),
+			step: "foo\n",
+		},
+		{
+			name: "data with escaped slashes",
+			page: fmt.Sprintf(`[{"id":1,"step_id":2,"data":"%s"}]`, strings.ReplaceAll(encode("???\n"), "/", `\/`)),
+			step: "???\n",
+		},
+		{
+			name: "multiple entries for a step",
+			page: testPage(testLog(1, 2, "foo\nba"), testLog(2, 2, "r\nbaz\n")),
+			step: "foo\nbar\nbaz\n",
+		},
+		{
+			name:    "entries for a service and a step",
+			page:    testPage(fmt.Sprintf(`{"id":1,"service_id":1,"data":"%s"}`, encode("redis\n")), testLog(2, 2, "foo\n")),
+			step:    "foo\n",
+			service: "redis\n",
+		},
+		{
+			name: "entry for a resource not analyzed",
+			page: testPage(testLog(1, 3, "bar\n"), testLog(2, 2, "foo\n")),
+			step: "foo\n",
+		},
+		{
+			name: "no data",
+			page: `[{"id":1,"step_id":2,"data":null}]`,
+		},
+		{
+			name: "empty data",
+			page: `[{"id":1,"step_id":2,"data":""}]`,
+		},
+		{
+			name: "empty page",
+			page: `[]`,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		// write the page split at every offset
+		for i := range len(test.page) + 1 {
+			t.Run(fmt.Sprintf("%s split at %d", test.name, i), func(t *testing.T) {
+				index := newLogIndex(minLogMemory)
+				step := index.Step(2)
+				service := index.Service(1)
+
+				d := newLogDecoder(index)
+
+				for _, part := range []string{test.page[:i], test.page[i:]} {
+					_, err := d.Write([]byte(part))
+					if err != nil {
+						t.Fatalf("Write returned err: %v", err)
+					}
+				}
+
+				_ = index.Close()
+
+				if step.Size != uint64(len(test.step)) {
+					t.Errorf("Size for step is %d, want %d", step.Size, len(test.step))
+				}
+
+				if got, want := step.Excerpt(), strings.TrimSuffix(test.step, "\n"); got != want {
+					t.Errorf("Excerpt for step is %q, want %q", got, want)
+				}
+
+				if got, want := service.Excerpt(), strings.TrimSuffix(test.service, "\n"); got != want {
+					t.Errorf("Excerpt for service is %q, want %q", got, want)
+				}
+
+				if len(index.steps) != 1 || len(index.services) != 1 {
+					t.Errorf("Write added metrics for resources that aren't analyzed")
+				}
+			})
+		}
+	}
+}
+
+func TestLogDecoder_Write_Pending(t *testing.T) {
+	// setup tests
+	//
+	// every 3 bytes of data are encoded as 4 characters,
+	// so these sizes fill the pending buffer around its cap
+	tests := []int{3*1024 - 3, 3 * 1024, 3*1024 + 1, 3*1024 + 3, 6 * 1024, 6*1024 + 2}
+
+	// run tests
+	for _, size := range tests {
+		t.Run(fmt.Sprintf("%d bytes", size), func(t *testing.T) {
+			// create lines of logs with the provided size
+			data := testLines(size/8, 8) + strings.Repeat("x", size%8)
+
+			index := newLogIndex(1 << 20)
+			stats := index.Step(2)
+
+			d := newLogDecoder(index)
+
+			// write the page in chunks that don't align with the pending buffer
+			page := []byte(testPage(testLog(1, 2, data)))
+
+			for len(page) > 0 {
+				n := min(1000, len(page))
+
+				_, err := d.Write(page[:n])
+				if err != nil {
+					t.Fatalf("Write returned err: %v", err)
+				}
+
+				page = page[n:]
+			}
+
+			_ = index.Close()
+
+			if stats.Size != uint64(size) {
+				t.Errorf("Size is %d, want %d", stats.Size, size)
+			}
+
+			if stats.Lines != size/8 {
+				t.Errorf("Lines is %d, want %d", stats.Lines, size/8)
+			}
+
+			// the last lines should be decoded intact
+			lines := strings.SplitAfter(strings.TrimSuffix(data, "\n"), "\n")
+			want := strings.Join(lines[len(lines)-excerptLines:], "")
+
+			if got := stats.Excerpt(); got != want {
+				t.Errorf("Excerpt is %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLogDecoder_Write_Invalid(t *testing.T) {
+	// setup types
+	index := newLogIndex(minLogMemory)
+	index.Step(2)
+
+	d := newLogDecoder(index)
+
+	_, err := d.Write([]byte(`[{"id":1,"step_id":2,"data":"not base64!"}]`))
+	if err == nil {
+		t.Errorf("Write should have returned err")
+	}
+}
+
+func TestLogDecoder_Write_Order(t *testing.T) {
+	// setup types
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+
+	index := newLogIndex(minLogMemory)
+	stats := index.Step(2)
+
+	d := newLogDecoder(index)
+
+	_, err := d.Write([]byte(testPage(testLog(2, 2, "bar\n"), testLog(1, 2, "foo\n"))))
+	if err != nil {
+		t.Fatalf("Write returned err: %v", err)
+	}
+
+	if stats.Lines != 2 {
+		t.Errorf("Lines is %d, want 2", stats.Lines)
+	}
+
+	var warnings int
+
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel {
+			warnings++
+		}
+	}
+
+	if warnings != 1 {
+		t.Errorf("warnings are %d, want 1", warnings)
+	}
+}
+
+func TestLogIndex(t *testing.T) {
+	// setup types
+	index := newLogIndex(minLogMemory)
+
+	if index.Step(1) != index.Step(1) {
+		t.Errorf("Step returned different metrics for the same step")
+	}
+
+	if index.Service(1) != index.Service(1) {
+		t.Errorf("Service returned different metrics for the same service")
+	}
+
+	if index.Step(1) == index.Service(1) {
+		t.Errorf("Step and Service returned the same metrics")
+	}
+
+	_, _ = index.Step(1).Write([]byte("foo"))
+
+	err := index.Close()
+	if err != nil {
+		t.Errorf("Close returned err: %v", err)
+	}
+
+	if index.Step(1).Excerpt() != "foo" {
+		t.Errorf("Close didn't analyze the last line of logs")
+	}
+}
+
+func TestLogStream(t *testing.T) {
+	// setup types
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("Link", `<`+r.URL.Path+`?page=2&per_page=100>; rel="next"`)
+			fmt.Fprint(w, testPage(testLog(1, 2, "foo\nerror: bar\n")))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":"something went wrong"}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := vela.NewClient(srv.URL, "test", nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		name    string
+		path    string
+		failure bool
+		lines   int
+		errors  int
+		next    int
+	}{
+		{
+			name:   "logs",
+			path:   "/api/v1/repos/foo/bar/builds/1/logs?page=1&per_page=100",
+			lines:  2,
+			errors: 1,
+			next:   2,
+		},
+		{
+			name:    "server error",
+			path:    "/api/v1/repos/foo/bar/builds/1/logs?page=2&per_page=100",
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index := newLogIndex(minLogMemory)
+			stats := index.Step(2)
+
+			resp, err := logStream(client, test.path, index)
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("logStream should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("logStream returned err: %v", err)
+			}
+
+			if stats.Lines != test.lines {
+				t.Errorf("Lines is %d, want %d", stats.Lines, test.lines)
+			}
+
+			if stats.Errors != test.errors {
+				t.Errorf("Errors is %d, want %d", stats.Errors, test.errors)
+			}
+
+			if got := linkPage(resp, "next"); got != test.next {
+				t.Errorf("next page is %d, want %d", got, test.next)
+			}
+		})
+	}
+}
+
+func TestPlugin_Logs(t *testing.T) {
+	// setup types
+	service := new(api.Service)
+	service.SetID(1)
+	service.SetStarted(1)
+
+	step := new(api.Step)
+	step.SetID(2)
+	step.SetStarted(1)
+
+	// the step never ran, so no memory is used for its logs
+	pending := new(api.Step)
+	pending.SetID(3)
+
+	// setup tests
+	tests := []struct {
+		name     string
+		fail     string
+		lines    int
+		excerpt  string
+		warnings int
+		failure  bool
+	}{
+		{
+			name:    "every page",
+			lines:   4,
+			excerpt: "foo\nbar\nbaz\nqux",
+		},
+		{
+			name:     "failing later page",
+			fail:     "2",
+			lines:    2,
+			excerpt:  "foo\nbar",
+			warnings: 1,
+		},
+		{
+			name:    "failing first page",
+			fail:    "1",
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+
+			// the logs for the step are split across entries and pages
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				page := r.URL.Query().Get("page")
+
+				if r.URL.Path != "/api/v1/repos/foo/bar/builds/1/logs" || page == test.fail {
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprint(w, `{"error":"unable to capture logs"}`)
+
+					return
+				}
+
+				switch page {
+				case "1":
+					w.Header().Set("Link", `<`+r.URL.Path+`?page=2&per_page=100>; rel="next"`)
+					fmt.Fprint(w, testPage(fmt.Sprintf(`{"id":1,"service_id":1,"data":"%s"}`, base64.StdEncoding.EncodeToString([]byte("redis\n"))), testLog(2, 2, "foo\n"), testLog(3, 2, "bar\n")))
+				case "2":
+					fmt.Fprint(w, testPage(testLog(4, 2, "baz\n"), testLog(5, 2, "qux\n")))
+				default:
+					fmt.Fprint(w, testPage())
+				}
+			}))
+			defer srv.Close()
+
+			client, err := vela.NewClient(srv.URL, "test", nil)
+			if err != nil {
+				t.Fatalf("unable to create client: %v", err)
+			}
+
+			p := &Plugin{Config: &Config{LogMemory: 4 * minLogMemory}}
+
+			index, err := p.logs(context.Background(), client, &Repo{Org: "foo", Name: "bar"}, 1, &[]api.Service{*service}, &[]api.Step{*step, *pending})
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("logs should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("logs returned err: %v", err)
+			}
+
+			stats := index.Step(2)
+
+			if stats.Lines != test.lines {
+				t.Errorf("Lines is %d, want %d", stats.Lines, test.lines)
+			}
+
+			if got := stats.Excerpt(); got != test.excerpt {
+				t.Errorf("Excerpt is %q, want %q", got, test.excerpt)
+			}
+
+			if got := index.Service(1).Excerpt(); got != "redis" {
+				t.Errorf("Excerpt for service is %q, want %q", got, "redis")
+			}
+
+			// the memory is split across the service and step that ran
+			if stats.limit != 2*minLogMemory {
+				t.Errorf("limit is %d, want %d", stats.limit, 2*minLogMemory)
+			}
+
+			var warnings int
+
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel {
+					warnings++
+				}
+			}
+
+			if warnings != test.warnings {
+				t.Errorf("warnings are %d, want %d", warnings, test.warnings)
+			}
+		})
+	}
+}
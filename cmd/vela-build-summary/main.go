@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"net/mail"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
 
+	"github.com/dustin/go-humanize"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
 
@@ -45,104 +47,56 @@ func main() {
 		},
 		Version: v.Semantic(),
 		Action:  run,
+		Commands: []*cli.Command{
+			{
+				Name:      "diff",
+				Usage:     "compare two builds, optionally from different repos",
+				ArgsUsage: "<base> <head>",
+				Description: "Compares the head build against the base build and outputs the summary for the head build with the changes from the base build.\n" +
+					"Each build is provided as a number for the repo (i.e. 41) or a repo and number (i.e. octocat/hello-world#41).",
+				Action: diff,
+			},
+		},
 	}
+
 	// Plugin Flags
+	cmd.Flags = flags()
 
-	cmd.Flags = []cli.Flag{
-
-		&cli.StringFlag{
-			Name:  "log.level",
-			Usage: "set log level - options: (trace|debug|info|warn|error|fatal|panic)",
-			Value: "info",
-			Sources: cli.NewValueSourceChain(
-				cli.EnvVar("PARAMETER_LOG_LEVEL"),
-				cli.EnvVar("BUILD_SUMMARY_LOG_LEVEL"),
-				cli.File("/vela/parameters/build-summary/log_level"),
-				cli.File("/vela/secrets/build-summary/log_level"),
-			),
-		},
+	// create a context canceled when the plugin is interrupted
+	//
+	// https://pkg.go.dev/os/signal#NotifyContext
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
-		// Build Flags
-
-		&cli.IntFlag{
-			Name:  "build.number",
-			Usage: "provide the number for the build",
-			Sources: cli.NewValueSourceChain(
-				cli.EnvVar("PARAMETER_NUMBER"),
-				cli.EnvVar("BUILD_SUMMARY_NUMBER"),
-				cli.EnvVar("VELA_BUILD_NUMBER"),
-				cli.File("/vela/parameters/build-summary/number"),
-				cli.File("/vela/secrets/build-summary/number"),
-			),
-		},
-
-		// Config Flags
-
-		&cli.StringFlag{
-			Name:  "config.server",
-			Usage: "Vela server to authenticate with",
-			Sources: cli.NewValueSourceChain(
-				cli.EnvVar("PARAMETER_SERVER"),
-				cli.EnvVar("BUILD_SUMMARY_SERVER"),
-				cli.EnvVar("VELA_ADDR"),
-				cli.File("/vela/parameters/build-summary/server"),
-				cli.File("/vela/secrets/build-summary/server"),
-			),
-			Required: true,
-			Action: func(_ context.Context, _ *cli.Command, v string) error {
-				if strings.HasSuffix(v, "/") {
-					return fmt.Errorf("invalid server address provided: address must not have trailing slash")
-				}
-
-				return nil
-			},
-		},
-		&cli.StringFlag{
-			Name:  "config.token",
-			Usage: "user token to authenticate with the Vela server",
-			Sources: cli.NewValueSourceChain(
-				cli.EnvVar("PARAMETER_TOKEN"),
-				cli.EnvVar("BUILD_SUMMARY_TOKEN"),
-				cli.EnvVar("VELA_NETRC_PASSWORD"),
-				cli.File("/vela/parameters/build-summary/token"),
-				cli.File("/vela/secrets/build-summary/token"),
-			),
-		},
+	err = cmd.Run(ctx, os.Args)
 
-		// Repo Flags
-
-		&cli.StringFlag{
-			Name:  "repo.org",
-			Usage: "provide the organization name for the build",
-			Sources: cli.NewValueSourceChain(
-				cli.EnvVar("PARAMETER_ORG"),
-				cli.EnvVar("BUILD_SUMMARY_ORG"),
-				cli.EnvVar("VELA_REPO_ORG"),
-				cli.File("/vela/parameters/build-summary/org"),
-				cli.File("/vela/parameters/build-summary/org"),
-			),
-		},
-		&cli.StringFlag{
-			Name:  "repo.name",
-			Usage: "provide the repository name for the build",
-			Sources: cli.NewValueSourceChain(
-				cli.EnvVar("PARAMETER_REPO"),
-				cli.EnvVar("BUILD_SUMMARY_REPO"),
-				cli.EnvVar("VELA_REPO_NAME"),
-				cli.File("/vela/parameters/build-summary/repo"),
-				cli.File("/vela/parameters/build-summary/repo"),
-			),
-		},
-	}
+	stop()
 
-	err = cmd.Run(context.Background(), os.Args)
 	if err != nil {
 		logrus.Fatal(err)
 	}
 }
 
 // run executes the plugin based off the configuration provided.
-func run(_ context.Context, c *cli.Command) error {
+func run(ctx context.Context, c *cli.Command) error {
+	// create the plugin
+	p, err := plugin(c)
+	if err != nil {
+		return err
+	}
+
+	// validate the plugin
+	err = p.Validate()
+	if err != nil {
+		return err
+	}
+
+	// execute the plugin
+	return p.Exec(ctx)
+}
+
+// plugin is a helper function to create the plugin from the
+// configuration provided, shared by the plugin and its commands.
+func plugin(c *cli.Command) (*Plugin, error) {
 	// set the log level for the plugin
 	switch c.String("log.level") {
 	case "t", "trace", "Trace", "TRACE":
@@ -169,32 +123,87 @@ func run(_ context.Context, c *cli.Command) error {
 		"registry": "https://hub.docker.com/r/target/vela-build-summary",
 	}).Info("Vela Build Summary Plugin")
 
+	// parse the maximum memory for analyzing logs
+	//
+	// https://pkg.go.dev/github.com/dustin/go-humanize?tab=doc#ParseBytes
+	logMemory, err := humanize.ParseBytes(c.String("config.log_memory"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid config log memory provided: %w", err)
+	}
+
 	// create the plugin
 	p := &Plugin{
 		// build configuration
 		Build: &Build{
 			Number: c.Int("build.number"),
+			Step:   c.Int("build.step"),
+		},
+		// compare configuration
+		Compare: &Compare{
+			Enabled:   c.Bool("compare.enabled"),
+			Threshold: c.Float("compare.threshold"),
 		},
 		// config configuration
 		Config: &Config{
-			AppName:    c.Name,
-			AppVersion: c.Version,
-			Server:     c.String("config.server"),
-			Token:      c.String("config.token"),
+			AppName:         c.Root().Name,
+			AppVersion:      c.Root().Version,
+			Concurrency:     c.Int("config.concurrency"),
+			LogMemory:       logMemory,
+			ResponseTimeout: c.Duration("config.response_timeout"),
+			RetryAttempts:   c.Int("config.retry_attempts"),
+			RetryMaxWait:    c.Duration("config.retry_max_wait"),
+			Server:          c.String("config.server"),
+			Token:           c.String("config.token"),
 		},
 		// repo configuration
 		Repo: &Repo{
 			Org:  c.String("repo.org"),
 			Name: c.String("repo.name"),
 		},
+		// wait configuration
+		Wait: &Wait{
+			Enabled:  c.Bool("wait.enabled"),
+			Interval: c.Duration("wait.interval"),
+			Timeout:  c.Duration("wait.timeout"),
+		},
 	}
 
-	// validate the plugin
-	err := p.Validate()
-	if err != nil {
-		return err
+	// check if a list of outputs was provided
+	if len(c.String("output.list")) > 0 {
+		// parse the list of outputs from the JSON provided
+		//
+		// https://pkg.go.dev/encoding/json#Unmarshal
+		err := json.Unmarshal([]byte(c.String("output.list")), &p.Outputs)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse outputs: %w", err)
+		}
+
+		// set the defaults for the outputs
+		for _, output := range p.Outputs {
+			if len(output.Format) == 0 {
+				output.Format = formatTable
+			}
+
+			if output.TimelineWidth == 0 {
+				output.TimelineWidth = c.Int("output.timeline_width")
+			}
+		}
+	} else {
+		// output configuration
+		p.Outputs = []*Output{
+			{
+				Directory:     c.String("output.directory"),
+				Endpoint:      c.String("output.endpoint"),
+				Format:        c.String("output.format"),
+				Path:          c.String("output.path"),
+				Pushgateway:   c.String("output.pushgateway"),
+				Template:      c.String("output.template"),
+				TemplateFile:  c.String("output.template_file"),
+				Timeline:      c.Bool("output.timeline"),
+				TimelineWidth: c.Int("output.timeline_width"),
+			},
+		}
 	}
 
-	// execute the plugin
-	return p.Exec()
+	return p, nil
 }
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+// markdownEscape is a helper function to escape characters
+// that would otherwise break a Markdown table cell.
+func markdownEscape(s string) string {
+	// escape pipes and collapse newlines so the cell stays on one row
+	return strings.NewReplacer("|", "\\|", "\r", "", "\n", " ").Replace(s)
+}
+
+// markdownStatus is a helper function to produce the status
+// of a resource with an emoji prepended to it.
+func markdownStatus(status string) string {
+	var emoji string
+
+	switch status {
+	case constants.StatusSuccess:
+		emoji = "✅"
+	case constants.StatusFailure:
+		emoji = "❌"
+	case constants.StatusError:
+		emoji = "❗"
+	case constants.StatusKilled, constants.StatusCanceled:
+		emoji = "🛑"
+	case constants.StatusSkipped:
+		emoji = "⏭️"
+	case constants.StatusRunning:
+		emoji = "🔄"
+	case constants.StatusPending, constants.StatusPendingApproval:
+		emoji = "⏳"
+	default:
+		emoji = "❔"
+	}
+
+	return fmt.Sprintf("%s %s", emoji, status)
+}
+
+// markdownCompare is a helper function to produce the cells with the values
+// from the previous build next to the change from the previous build for a
+// row in the Markdown table.
+//
+// No cells are produced if the build isn't compared against a previous build.
+func markdownCompare(r *Resource, compare bool) string {
+	// check if the build is compared against a previous build
+	if !compare {
+		return ""
+	}
+
+	// capture the status from the previous build
+	status := r.PreviousStatus()
+	if r.Previous != nil {
+		status = markdownStatus(status)
+	}
+
+	return fmt.Sprintf(" %s | %s | %s | %s | %s | %s | %s |", status, r.PreviousElapsed(), r.PreviousLines(), r.PreviousSize(), r.DurationDelta(), r.LinesDelta(), r.SizeDelta())
+}
+
+// markdownRow is a helper function to produce a resource row in the Markdown table.
+func markdownRow(buf *bytes.Buffer, r *Resource, compare bool) {
+	logrus.Tracef("adding %s %s to Markdown table", r.Type, r.Name)
+
+	fmt.Fprintf(buf, "| %s | %s | %d | %s | %s | %s | %d | %s | %s | %s |%s\n",
+		r.Type,
+		markdownEscape(r.Name),
+		r.Number,
+		markdownStatus(r.Status),
+		r.Wait(),
+		r.Elapsed(),
+		r.Lines,
+		humanize.Bytes(r.Size),
+		r.Throughput(),
+		r.Leeway(),
+		markdownCompare(r, compare),
+	)
+}
+
+// markdownStageRow is a helper function to produce a stage subtotal row in the Markdown table.
+func markdownStageRow(buf *bytes.Buffer, s *Stage, compare bool) {
+	logrus.Tracef("adding stage %s to Markdown table", s.Name)
+
+	fmt.Fprintf(buf, "| _stage_ | _%s_ | | %s | | _%s_ | _%d_ | _%s_ | _%s_ | |%s\n",
+		markdownEscape(stageNote(s)),
+		markdownStatus(s.Totals.Status),
+		s.Totals.Elapsed(),
+		s.Totals.Lines,
+		humanize.Bytes(s.Totals.Size),
+		s.Totals.Throughput(),
+		markdownCompare(s.Totals, compare),
+	)
+}
+
+// markdownOutput is a helper function to output the provided build summary
+// as a GitHub-flavored Markdown document.
+//
+// The document includes a header with information on the build, a table
+// of the services and steps in the build and a footer with the totals for
+// the build. This is suitable for pull request comments and job summaries.
+//
+// If enabled, a Mermaid Gantt diagram of the build is added after the table.
+func markdownOutput(w io.Writer, s *Summary, timeline bool) error {
+	logrus.Debug("creating Markdown document for build summary")
+
+	// create a buffer to render the document in
+	buf := new(bytes.Buffer)
+
+	logrus.Trace("adding header to Markdown document")
+	// add the build header to the document
+	fmt.Fprintf(buf, "## Build #%d %s\n\n", s.Build.GetNumber(), markdownStatus(s.Build.GetStatus()))
+
+	// capture the commit for the build
+	commit := s.Build.GetCommit()
+
+	// shorten the commit for display purposes
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+
+	fmt.Fprintln(buf, "| Event | Branch | Commit | Author |")
+	fmt.Fprintln(buf, "| ----- | ------ | ------ | ------ |")
+	fmt.Fprintf(buf, "| %s | %s | `%s` | %s |\n\n",
+		markdownEscape(s.Build.GetEvent()),
+		markdownEscape(s.Build.GetBranch()),
+		commit,
+		markdownEscape(s.Build.GetAuthor()),
+	)
+
+	// check if the build has a link to the Vela UI
+	if len(s.Build.GetLink()) > 0 {
+		fmt.Fprintf(buf, "[View build in Vela](%s)\n\n", s.Build.GetLink())
+	}
+
+	logrus.Trace("adding resources to Markdown document")
+	// set of build fields we display in a table
+	fmt.Fprint(buf, "| Type | Name | Number | Status | Queued | Duration | Log Lines | Log Size | Log Rate | Slack |")
+
+	// check if the build was compared against a previous build
+	compare := s.Previous != nil
+	if compare {
+		fmt.Fprintln(buf, " Base Status | Base Duration | Base Log Lines | Base Log Size | Δ Duration | Δ Log Lines | Δ Log Size |")
+		fmt.Fprintln(buf, "| ---- | ---- | -----: | ------ | -----: | -------: | --------: | -------: | -------: | ----: | ----------- | ------------: | -------------: | ------------: | ---------: | ----------: | ---------: |")
+	} else {
+		fmt.Fprintln(buf)
+		fmt.Fprintln(buf, "| ---- | ---- | -----: | ------ | -----: | -------: | --------: | -------: | -------: | ----: |")
+	}
+
+	// add the service rows to the document
+	for _, r := range s.Services {
+		markdownRow(buf, r, compare)
+	}
+
+	// check if the steps ran in stages
+	if len(s.Stages) > 0 {
+		// add the step rows grouped by stage to the document
+		for _, stage := range s.Stages {
+			for _, r := range stage.Steps {
+				markdownRow(buf, r, compare)
+			}
+
+			markdownStageRow(buf, stage, compare)
+		}
+	} else {
+		// add the step rows to the document
+		for _, r := range s.Steps {
+			markdownRow(buf, r, compare)
+		}
+	}
+
+	logrus.Trace("adding footer to Markdown document")
+	// add the build totals to the document
+	fmt.Fprintf(buf, "| **build** | | **%d** | **%s** | **%s** | **%s** | **%d** | **%s** | **%s** | |%s\n",
+		s.Totals.Number,
+		markdownStatus(s.Totals.Status),
+		s.Totals.Wait(),
+		s.Totals.Elapsed(),
+		s.Totals.Lines,
+		humanize.Bytes(s.Totals.Size),
+		s.Totals.Throughput(),
+		markdownCompare(s.Totals, compare),
+	)
+
+	// check if any resources were excluded from the totals
+	if s.Excluded > 0 {
+		fmt.Fprintf(buf, "\n_%d services and steps that aren't complete are excluded from the totals._\n", s.Excluded)
+	}
+
+	// add the breakdown of where the time for the build was spent
+	fmt.Fprintf(buf, "\n**Breakdown** (%s total): %s\n", s.Breakdown.Total(), s.Breakdown)
+
+	// add the parallelism and utilization of the build
+	fmt.Fprintf(buf, "\n**Utilization**: %s\n", s.Utilization)
+
+	// check if the build has a critical path
+	if len(s.CriticalPath) > 0 {
+		fmt.Fprintf(buf, "\n**Critical path** (%s): `%s`\n", criticalSpan(s), strings.Join(criticalNames(s), "` → `"))
+	}
+
+	// check if the build was compared against a previous build
+	if compare {
+		fmt.Fprintf(buf, "\n**Compared to build %s**: %s\n", compareBuild(s), markdownEscape(compareNote(s)))
+	}
+
+	// check if the timeline should be added to the document
+	if timeline {
+		logrus.Trace("adding timeline to Markdown document")
+
+		fmt.Fprintf(buf, "\n```mermaid\n%s```\n", mermaidGantt(s))
+	}
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownEscape(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "plain",
+			s:    "build",
+			want: "build",
+		},
+		{
+			name: "pipes",
+			s:    "build | test",
+			want: "build \\| test",
+		},
+		{
+			name: "newlines",
+			s:    "build\r\ntest\n",
+			want: "build test ",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := markdownEscape(test.s)
+
+			if got != test.want {
+				t.Errorf("markdownEscape is %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownOutput(t *testing.T) {
+	// setup types
+	s := testBuildSummary()
+
+	s.Build.SetEvent("push")
+	s.Build.SetBranch("main")
+	s.Build.SetCommit("48afb5bdc41ad69bf22588491333f7cf71135163")
+	s.Build.SetAuthor("octocat")
+	s.Build.SetLink("https://vela.example.com/foo/bar/1")
+
+	want := strings.Join([]string{
+		"## Build #1 ❌ failure",
+		"",
+		"| Event | Branch | Commit | Author |",
+		"| ----- | ------ | ------ | ------ |",
+		"| push | main | `48afb5b` | octocat |",
+		"",
+		"[View build in Vela](https://vela.example.com/foo/bar/1)",
+		"",
+		"| Type | Name | Number | Status | Queued | Duration | Log Lines | Log Size | Log Rate | Slack |",
+		"| ---- | ---- | -----: | ------ | -----: | -------: | --------: | -------: | -------: | ----: |",
+		"| service | postgres | 1 | ✅ success | 10s | 1m0s | 1 | 23 B | 0 B/s | - |",
+		"| step | clone | 1 | ✅ success | 10s | 10s | 2 | 37 B | 3 B/s | critical |",
+		"| _stage_ | _init (10s step time)_ | | ✅ success | | _10s_ | _2_ | _37 B_ | _3 B/s_ | |",
+		"| step | test | 2 | ❌ failure | 20s | 40s | 2 | 33 B | 0 B/s | critical |",
+		"| _stage_ | _test (40s step time)_ | | ❌ failure | | _40s_ | _2_ | _33 B_ | _0 B/s_ | |",
+		"| step | publish | 3 | ⏭️ skipped | - | - | 0 | 0 B | - | - |",
+		"| _stage_ | _publish (0s step time)_ | | ⏭️ skipped | | _-_ | _0_ | _0 B_ | _-_ | |",
+		"| **build** | | **1** | **❌ failure** | **8s** | **1m0s** | **5** | **93 B** | **1 B/s** | |",
+		"",
+		"_1 services and steps that aren't complete are excluded from the totals._",
+		"",
+		"**Breakdown** (1m10s total): 2s pending, 8s queued for a worker, 10s between steps, 50s running",
+		"",
+		"**Utilization**: 1 peak and 0.83 average concurrent steps, 50s step time over 1m0s wall-clock, 17% idle",
+		"",
+		"**Critical path** (50s): `clone` → `test`",
+		"",
+	}, "\n")
+
+	buf := new(bytes.Buffer)
+
+	err := markdownOutput(buf, s, false)
+	if err != nil {
+		t.Fatalf("markdownOutput returned err: %v", err)
+	}
+
+	if buf.String() != want {
+		t.Errorf("markdownOutput is %s, want %s", buf.String(), want)
+	}
+}
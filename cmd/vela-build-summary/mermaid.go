@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+// mermaidEscape is a helper function to escape characters
+// that would otherwise break a Mermaid Gantt diagram.
+func mermaidEscape(s string) string {
+	// replace the characters used to separate the fields of a task
+	return strings.NewReplacer(": ", " ", ":", " ", ";", " ", "#", " ", "\n", " ").Replace(s)
+}
+
+// mermaidTag is a helper function to produce the tag
+// for a task in the Gantt diagram based off its status.
+func mermaidTag(status string) string {
+	switch status {
+	case constants.StatusSuccess:
+		return "done, "
+	case constants.StatusFailure, constants.StatusError, constants.StatusKilled:
+		return "crit, "
+	case constants.StatusRunning:
+		return "active, "
+	default:
+		return ""
+	}
+}
+
+// mermaidGantt is a helper function to produce a Mermaid Gantt
+// diagram describing when each resource in the build ran.
+//
+// Services are displayed in a single section, and steps are
+// displayed in a section for the stage they ran in.
+//
+// If the build was compared against a previous build, the diagram
+// is titled with the previous build and the resources that regressed
+// from the previous build are marked.
+//
+// https://mermaid.js.org/syntax/gantt.html
+func mermaidGantt(s *Summary) string {
+	logrus.Trace("creating Mermaid Gantt diagram for build summary")
+
+	// create a buffer to render the diagram in
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintln(buf, "gantt")
+	// create a variable to track the title for the diagram
+	title := fmt.Sprintf("%s build %d", s.Build.GetRepo().GetFullName(), s.Totals.Number)
+
+	// check if the build was compared against a previous build
+	if s.Previous != nil {
+		title = fmt.Sprintf("%s compared to %s build %d", title, s.Previous.Build.GetRepo().GetFullName(), s.Previous.Totals.Number)
+	}
+
+	fmt.Fprintf(buf, "    title %s\n", mermaidEscape(title))
+	fmt.Fprintln(buf, "    dateFormat X")
+	fmt.Fprintln(buf, "    axisFormat %H:%M:%S")
+
+	for _, group := range timelineGroups(s) {
+		// create a variable to track if the section was added
+		section := false
+
+		for _, r := range group.Resources {
+			// check if the resource started running
+			if r.Started == 0 {
+				continue
+			}
+
+			// add the section for the group before its first task
+			if !section {
+				fmt.Fprintf(buf, "    section %s\n", mermaidEscape(group.Name))
+
+				section = true
+			}
+
+			// use the current time for resources that haven't finished
+			finished := r.Finished
+			if finished == 0 {
+				finished = time.Now().Unix()
+			}
+
+			// ensure every resource that ran is displayed for at least one second
+			duration := max(finished-r.Started, 1)
+
+			// mark the resources that regressed from the previous build
+			name := r.Name
+			if len(r.Regressions) > 0 {
+				name = fmt.Sprintf("%s %s", name, regressionMarker)
+			}
+
+			fmt.Fprintf(buf, "    %s :%s%s-%d, %d, %ds\n",
+				mermaidEscape(name),
+				mermaidTag(r.Status),
+				r.Type,
+				r.Number,
+				r.Started,
+				duration,
+			)
+		}
+	}
+
+	return buf.String()
+}
+
+// mermaidOutput is a helper function to output the provided build
+// summary as a Mermaid Gantt diagram.
+//
+// The diagram renders natively in GitHub and GitLab Markdown.
+func mermaidOutput(w io.Writer, s *Summary) error {
+	logrus.Debug("creating Mermaid document for build summary")
+
+	_, err := io.WriteString(w, mermaidGantt(s))
+
+	return err
+}
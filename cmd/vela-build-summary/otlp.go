@@ -0,0 +1,394 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+const (
+	// otlpPath represents the path for exporting traces
+	// to a collector with the OTLP/HTTP protocol.
+	otlpPath = "/v1/traces"
+
+	// otlpKindInternal represents the kind for spans
+	// exported with the OTLP protocol.
+	otlpKindInternal = 1
+
+	// otlpStatusUnset represents the unset status code for
+	// spans exported with the OTLP protocol.
+	otlpStatusUnset = 0
+	// otlpStatusOk represents the ok status code for
+	// spans exported with the OTLP protocol.
+	otlpStatusOk = 1
+	// otlpStatusError represents the error status code for
+	// spans exported with the OTLP protocol.
+	otlpStatusError = 2
+)
+
+// otlpDocument represents the OTLP JSON document produced
+// for the build summary.
+//
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpDocument struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpResourceSpans represents the spans for a resource
+// in the OTLP JSON document.
+type otlpResourceSpans struct {
+	Resource   *otlpResource     `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+}
+
+// otlpResource represents the resource that produced
+// the spans in the OTLP JSON document.
+type otlpResource struct {
+	Attributes []*otlpAttribute `json:"attributes"`
+}
+
+// otlpScopeSpans represents the spans for an instrumentation
+// scope in the OTLP JSON document.
+type otlpScopeSpans struct {
+	Scope *otlpScope  `json:"scope"`
+	Spans []*otlpSpan `json:"spans"`
+}
+
+// otlpScope represents the instrumentation scope that
+// produced the spans in the OTLP JSON document.
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// otlpSpan represents a span in the OTLP JSON document.
+type otlpSpan struct {
+	TraceID           string           `json:"traceId"`
+	SpanID            string           `json:"spanId"`
+	ParentSpanID      string           `json:"parentSpanId,omitempty"`
+	Name              string           `json:"name"`
+	Kind              int              `json:"kind"`
+	StartTimeUnixNano int64            `json:"startTimeUnixNano,string"`
+	EndTimeUnixNano   int64            `json:"endTimeUnixNano,string"`
+	Attributes        []*otlpAttribute `json:"attributes"`
+	Status            *otlpStatus      `json:"status"`
+}
+
+// otlpStatus represents the status of a span in the OTLP JSON document.
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpAttribute represents an attribute in the OTLP JSON document.
+type otlpAttribute struct {
+	Key   string     `json:"key"`
+	Value *otlpValue `json:"value"`
+}
+
+// otlpValue represents the value of an attribute in the OTLP JSON document.
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+// otlpString is a helper function to produce
+// a string attribute for the OTLP JSON document.
+func otlpString(key, value string) *otlpAttribute {
+	return &otlpAttribute{Key: key, Value: &otlpValue{StringValue: &value}}
+}
+
+// otlpInt is a helper function to produce an
+// integer attribute for the OTLP JSON document.
+func otlpInt(key string, value int64) *otlpAttribute {
+	// integers are encoded as strings in the OTLP JSON document
+	v := strconv.FormatInt(value, 10)
+
+	return &otlpAttribute{Key: key, Value: &otlpValue{IntValue: &v}}
+}
+
+// otlpID is a helper function to produce a deterministic identifier
+// of the provided size in bytes from the provided parts.
+//
+// Using deterministic identifiers ensures exporting the same
+// build more than once produces the same trace and spans.
+func otlpID(size int, parts ...any) string {
+	sum := sha256.Sum256([]byte(fmt.Sprint(parts...)))
+
+	return hex.EncodeToString(sum[:size])
+}
+
+// otlpTime is a helper function to convert a unix timestamp
+// into a timestamp in nanoseconds for the OTLP JSON document.
+func otlpTime(t int64) int64 {
+	// use the current time for resources that haven't finished
+	if t == 0 {
+		t = time.Now().Unix()
+	}
+
+	return time.Unix(t, 0).UnixNano()
+}
+
+// otlpSpanStatus is a helper function to convert the status
+// of a resource into the status of a span.
+func otlpSpanStatus(r *Resource) *otlpStatus {
+	switch r.Status {
+	case constants.StatusSuccess:
+		return &otlpStatus{Code: otlpStatusOk}
+	case constants.StatusFailure, constants.StatusError, constants.StatusKilled:
+		// use the status for resources without an error message
+		msg := r.Error
+		if len(msg) == 0 {
+			msg = r.Status
+		}
+
+		return &otlpStatus{Code: otlpStatusError, Message: msg}
+	default:
+		return &otlpStatus{Code: otlpStatusUnset}
+	}
+}
+
+// otlpResourceSpan is a helper function to convert a
+// resource into a span for the OTLP JSON document.
+func otlpResourceSpan(traceID, parentID string, r *Resource) *otlpSpan {
+	logrus.Tracef("adding %s %s to OTLP document", r.Type, r.Name)
+
+	span := &otlpSpan{
+		TraceID:           traceID,
+		SpanID:            otlpID(8, traceID, r.Type, r.Number),
+		ParentSpanID:      parentID,
+		Name:              fmt.Sprintf("%s %s", r.Type, r.Name),
+		Kind:              otlpKindInternal,
+		StartTimeUnixNano: otlpTime(r.Started),
+		EndTimeUnixNano:   otlpTime(r.Finished),
+		Attributes: []*otlpAttribute{
+			otlpString("vela.type", r.Type),
+			otlpString("vela.name", r.Name),
+			otlpInt("vela.number", int64(r.Number)),
+			otlpString("vela.status", r.Status),
+			otlpString("vela.stage", r.Stage),
+			otlpString("vela.image", r.Image),
+			otlpString("vela.host", r.Host),
+			otlpInt("vela.exit_code", int64(r.ExitCode)),
+			otlpInt("vela.log.bytes", int64(r.Size)), //nolint:gosec // log sizes don't overflow an int64
+			otlpInt("vela.log.errors", int64(r.Errors)),
+			otlpInt("vela.log.lines", int64(r.Lines)),
+		},
+		Status: otlpSpanStatus(r),
+	}
+
+	// check if the resource ran in the previous build
+	if r.Previous != nil {
+		span.Attributes = append(span.Attributes,
+			otlpString("vela.previous.status", r.Previous.Status),
+			otlpInt("vela.previous.duration_seconds", int64(r.Previous.Duration.Seconds())),
+			otlpInt("vela.previous.log.bytes", int64(r.Previous.Size)), //nolint:gosec // log sizes don't overflow an int64
+			otlpInt("vela.previous.log.lines", int64(r.Previous.Lines)),
+			otlpInt("vela.delta.duration_seconds", int64((r.Duration-r.Previous.Duration).Seconds())),
+			otlpInt("vela.delta.log.bytes", int64(r.Size)-int64(r.Previous.Size)), //nolint:gosec // log sizes don't overflow an int64
+			otlpInt("vela.delta.log.lines", int64(r.Lines-r.Previous.Lines)),
+		)
+	}
+
+	// check if the resource regressed from the previous build
+	if len(r.Regressions) > 0 {
+		span.Attributes = append(span.Attributes, otlpString("vela.regressions", strings.Join(r.Regressions, ",")))
+	}
+
+	return span
+}
+
+// otlpSpans is a helper function to convert the provided
+// build summary into spans for the OTLP JSON document.
+//
+// The build is the root span, with a child span for each
+// service and stage in the build. Each step is a child
+// span of the stage it ran in.
+//
+// If the build was compared against a previous build, the
+// values from and change from the previous build are added
+// to the attributes for each span.
+func otlpSpans(s *Summary) []*otlpSpan {
+	// create the trace identifier for the build
+	traceID := otlpID(16, s.Build.GetRepo().GetFullName(), s.Totals.Number)
+
+	// create the root span for the build
+	root := otlpResourceSpan(traceID, "", s.Totals)
+	root.Name = fmt.Sprintf("%s #%d", s.Build.GetRepo().GetFullName(), s.Totals.Number)
+	root.Attributes = append(root.Attributes,
+		otlpString("vela.build.event", s.Build.GetEvent()),
+		otlpString("vela.build.branch", s.Build.GetBranch()),
+		otlpString("vela.build.commit", s.Build.GetCommit()),
+	)
+
+	// check if the build was compared against a previous build
+	if s.Previous != nil {
+		root.Attributes = append(root.Attributes, otlpString("vela.previous.build", compareBuild(s)))
+	}
+
+	spans := []*otlpSpan{root}
+
+	// add the spans for the services
+	for _, r := range s.Services {
+		// check if the service started running
+		if r.Started == 0 {
+			continue
+		}
+
+		spans = append(spans, otlpResourceSpan(traceID, root.SpanID, r))
+	}
+
+	// create a map to track the span for each stage
+	stages := make(map[string]*otlpSpan)
+
+	// add the spans for the steps
+	for _, r := range s.Steps {
+		// check if the step started running
+		if r.Started == 0 {
+			continue
+		}
+
+		// default to the build as the parent of the step
+		parent := root
+
+		// check if the step ran in a stage
+		if len(r.Stage) > 0 {
+			stage, ok := stages[r.Stage]
+			if !ok {
+				// create the span for the stage
+				stage = &otlpSpan{
+					TraceID:           traceID,
+					SpanID:            otlpID(8, traceID, "stage", r.Stage),
+					ParentSpanID:      root.SpanID,
+					Name:              fmt.Sprintf("stage %s", r.Stage),
+					Kind:              otlpKindInternal,
+					StartTimeUnixNano: otlpTime(r.Started),
+					EndTimeUnixNano:   otlpTime(r.Finished),
+					Attributes: []*otlpAttribute{
+						otlpString("vela.type", "stage"),
+						otlpString("vela.name", r.Stage),
+					},
+					Status: &otlpStatus{Code: otlpStatusUnset},
+				}
+
+				stages[r.Stage] = stage
+				spans = append(spans, stage)
+			}
+
+			// extend the span for the stage to include the step
+			stage.StartTimeUnixNano = min(stage.StartTimeUnixNano, otlpTime(r.Started))
+			stage.EndTimeUnixNano = max(stage.EndTimeUnixNano, otlpTime(r.Finished))
+
+			// mark the stage as failed if any of the steps failed
+			if status := otlpSpanStatus(r); status.Code == otlpStatusError {
+				stage.Status = status
+			}
+
+			parent = stage
+		}
+
+		spans = append(spans, otlpResourceSpan(traceID, parent.SpanID, r))
+	}
+
+	return spans
+}
+
+// otlpOutput is a helper function to output the provided build
+// summary as a trace in the OTLP JSON format.
+//
+// The document can be sent to any collector supporting the
+// OTLP/HTTP protocol with JSON encoding, or archived as a file.
+func otlpOutput(w io.Writer, s *Summary) error {
+	logrus.Debug("creating OTLP document for build summary")
+
+	// create the document with the spans for the build
+	doc := &otlpDocument{
+		ResourceSpans: []*otlpResourceSpans{
+			{
+				Resource: &otlpResource{
+					Attributes: []*otlpAttribute{
+						otlpString("service.name", "vela"),
+						otlpString("vela.org", s.Build.GetRepo().GetOrg()),
+						otlpString("vela.repo", s.Build.GetRepo().GetName()),
+					},
+				},
+				ScopeSpans: []*otlpScopeSpans{
+					{
+						Scope: &otlpScope{Name: "github.com/go-vela/vela-build-summary"},
+						Spans: otlpSpans(s),
+					},
+				},
+			},
+		},
+	}
+
+	// serialize the document as JSON
+	//
+	// https://pkg.go.dev/encoding/json#NewEncoder
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// otlpPush is a helper function to export the provided build summary
+// as a trace to a collector with the OTLP/HTTP protocol.
+//
+// The trace is only exported with the JSON encoding, since the
+// protobuf encoding and the OTLP/gRPC protocol aren't supported.
+func otlpPush(ctx context.Context, endpoint string, s *Summary) error {
+	// create the URL for exporting traces to the collector
+	u := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(u, otlpPath) {
+		u += otlpPath
+	}
+
+	logrus.Infof("exporting OTLP trace for build summary to %s", u)
+
+	// create a buffer to render the trace in
+	buf := new(bytes.Buffer)
+
+	err := otlpOutput(buf, s)
+	if err != nil {
+		return err
+	}
+
+	// create the request to export the trace
+	//
+	// https://pkg.go.dev/net/http#NewRequestWithContext
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, buf)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// send the request to the collector
+	//
+	// https://pkg.go.dev/net/http#Client.Do
+	resp, err := outputClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// check if the collector accepted the trace
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("unable to export trace to %s: %s: %s", endpoint, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
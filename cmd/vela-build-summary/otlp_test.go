@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOTLPPush(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		endpoint string
+		status   int
+		failure  bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+		},
+		{
+			name:     "endpoint with path",
+			endpoint: "/v1/traces/",
+			status:   http.StatusOK,
+		},
+		{
+			name:    "rejected",
+			status:  http.StatusUnsupportedMediaType,
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != otlpPath {
+					t.Errorf("request path is %s, want %s", r.URL.Path, otlpPath)
+				}
+
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("request content type is %s, want application/json", r.Header.Get("Content-Type"))
+				}
+
+				doc := map[string]any{}
+
+				err := json.NewDecoder(r.Body).Decode(&doc)
+				if err != nil {
+					t.Errorf("unable to decode request body: %v", err)
+				}
+
+				if _, ok := doc["resourceSpans"]; !ok {
+					t.Errorf("request body is missing resourceSpans: %v", doc)
+				}
+
+				w.WriteHeader(test.status)
+			}))
+			defer srv.Close()
+
+			err := otlpPush(context.Background(), srv.URL+test.endpoint, testBuildSummary())
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("otlpPush should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("otlpPush returned err: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// formatChrome defines the format for outputting the build summary as a Chrome trace.
+	formatChrome = "chrome"
+	// formatCSV defines the format for outputting the build summary as CSV.
+	formatCSV = "csv"
+	// formatHTML defines the format for outputting the build summary as HTML.
+	formatHTML = "html"
+	// formatJSON defines the format for outputting the build summary as JSON.
+	formatJSON = "json"
+	// formatJUnit defines the format for outputting the build summary as JUnit XML.
+	formatJUnit = "junit"
+	// formatMarkdown defines the format for outputting the build summary as Markdown.
+	formatMarkdown = "markdown"
+	// formatMermaid defines the format for outputting the build summary as a Mermaid diagram.
+	formatMermaid = "mermaid"
+	// formatOTLP defines the format for outputting the build summary as an OTLP trace.
+	formatOTLP = "otlp"
+	// formatPrometheus defines the format for outputting the build summary as Prometheus metrics.
+	formatPrometheus = "prometheus"
+	// formatTable defines the format for outputting the build summary as a table.
+	formatTable = "table"
+	// formatTemplate defines the format for outputting the build summary with a template.
+	formatTemplate = "template"
+	// formatTSV defines the format for outputting the build summary as TSV.
+	formatTSV = "tsv"
+)
+
+// outputTimeout represents the maximum duration to wait for
+// a Pushgateway or collector to accept the build summary.
+const outputTimeout = 30 * time.Second
+
+// outputClient represents the HTTP client used to send
+// the build summary to a Pushgateway or collector.
+var outputClient = &http.Client{Timeout: outputTimeout}
+
+// outputName represents the name of the file written
+// when a directory is provided for the build summary.
+const outputName = "build-summary"
+
+// outputExtensions represents the file extension used for
+// each format when a directory is provided for the build summary.
+var outputExtensions = map[string]string{
+	formatChrome:     "chrome.json",
+	formatCSV:        "csv",
+	formatHTML:       "html",
+	formatJSON:       "json",
+	formatJUnit:      "xml",
+	formatMarkdown:   "md",
+	formatMermaid:    "mmd",
+	formatOTLP:       "otlp.json",
+	formatPrometheus: "prom",
+	formatTable:      "txt",
+	formatTemplate:   "txt",
+	formatTSV:        "tsv",
+}
+
+// Output represents the plugin configuration for output information.
+type Output struct {
+	// format to output the build summary in
+	Format string `json:"format"`
+	// directory to write the build summary to
+	Directory string `json:"directory"`
+	// collector endpoint to export the OTLP trace for the build summary to
+	Endpoint string `json:"endpoint"`
+	// path to a file to write the build summary to
+	Path string `json:"path"`
+	// Pushgateway to push Prometheus metrics for the build summary to
+	Pushgateway string `json:"pushgateway"`
+	// Go template to output the build summary with
+	Template string `json:"template"`
+	// path to a file containing a Go template to output the build summary with
+	TemplateFile string `json:"template_file"`
+	// enables outputting a timeline after the table for the build summary
+	Timeline bool `json:"timeline"`
+	// number of columns to fit the timeline for the build summary in
+	TimelineWidth int `json:"timeline_width"`
+}
+
+// Write outputs the build summary in the configured format.
+//
+// If a path is provided, the build summary is written to that file.
+// If a directory is provided, the build summary is written to a file
+// named after the format in that directory. Otherwise, the build
+// summary is written to stdout.
+func (o *Output) Write(ctx context.Context, s *Summary) error {
+	logrus.Tracef("writing %s output for build summary", o.Format)
+
+	switch {
+	// check if the OTLP trace should be exported to a collector
+	case o.Format == formatOTLP && len(o.Endpoint) > 0:
+		return otlpPush(ctx, o.Endpoint, s)
+	// check if the Prometheus metrics should be pushed to a Pushgateway
+	case o.Format == formatPrometheus && len(o.Pushgateway) > 0:
+		return prometheusPush(ctx, o.Pushgateway, s)
+	}
+
+	// capture the path to write the build summary to
+	path := o.Path
+
+	// check if a directory is provided for the build summary
+	if len(o.Directory) > 0 {
+		path = filepath.Join(o.Directory, fmt.Sprintf("%s.%s", outputName, outputExtensions[o.Format]))
+	}
+
+	// check if a path is provided for the build summary
+	if len(path) == 0 {
+		return o.render(os.Stdout, s)
+	}
+
+	logrus.Infof("writing %s build summary to %s", o.Format, path)
+
+	// create the parent directories for the path
+	//
+	// https://pkg.go.dev/os#MkdirAll
+	err := os.MkdirAll(filepath.Dir(path), 0750)
+	if err != nil {
+		return err
+	}
+
+	// create a temporary file in the same directory as the path
+	//
+	// the build summary is renamed into place once it's written, so
+	// readers like the textfile collector for the node exporter never
+	// see a partially written file
+	//
+	// https://pkg.go.dev/os#CreateTemp
+	f, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*", filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+
+	err = o.render(f, s)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	// set the permissions the file would have been created with
+	//
+	// https://pkg.go.dev/os#File.Chmod
+	//
+	//nolint:gosec // the build summary is read by steps running as other users
+	err = f.Chmod(0644)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	// replace the file for the path with the build summary
+	//
+	// https://pkg.go.dev/os#Rename
+	err = os.Rename(f.Name(), filepath.Clean(path))
+	if err != nil {
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	return nil
+}
+
+// render is a helper function to output the build
+// summary in the configured format to the writer.
+func (o *Output) render(w io.Writer, s *Summary) error {
+	switch o.Format {
+	case formatChrome:
+		return chromeOutput(w, s)
+	case formatCSV:
+		return csvOutput(w, s, ',')
+	case formatHTML:
+		return htmlOutput(w, s)
+	case formatJSON:
+		return jsonOutput(w, s)
+	case formatJUnit:
+		return junitOutput(w, s)
+	case formatMarkdown:
+		return markdownOutput(w, s, o.Timeline)
+	case formatMermaid:
+		return mermaidOutput(w, s)
+	case formatOTLP:
+		return otlpOutput(w, s)
+	case formatPrometheus:
+		return prometheusOutput(w, s)
+	case formatTemplate:
+		// capture the template for the output
+		text := o.Template
+
+		// check if the template should be read from a file
+		if len(o.TemplateFile) > 0 {
+			// read the template from the file
+			//
+			// https://pkg.go.dev/os#ReadFile
+			data, err := os.ReadFile(filepath.Clean(o.TemplateFile))
+			if err != nil {
+				return err
+			}
+
+			text = string(data)
+		}
+
+		return templateOutput(w, s, text)
+	case formatTSV:
+		return csvOutput(w, s, '\t')
+	default:
+		err := table(w, s)
+		if err != nil {
+			return err
+		}
+
+		// check if the timeline should be output after the table
+		if o.Timeline {
+			return timeline(w, s, o.TimelineWidth)
+		}
+
+		return nil
+	}
+}
+
+// Validate verifies the Output is properly configured.
+func (o *Output) Validate() error {
+	logrus.Trace("validating output plugin configuration")
+
+	// verify format is supported
+	switch o.Format {
+	case formatChrome, formatCSV, formatHTML, formatJSON, formatJUnit, formatMarkdown, formatMermaid, formatOTLP, formatPrometheus, formatTable, formatTemplate, formatTSV:
+	default:
+		return fmt.Errorf("invalid output format provided: %s", o.Format)
+	}
+
+	// verify path and directory are not both provided
+	if len(o.Path) > 0 && len(o.Directory) > 0 {
+		return fmt.Errorf("output path and directory are mutually exclusive")
+	}
+
+	// check if a template is requested
+	if o.Format == formatTemplate {
+		// verify exactly one template is provided
+		if len(o.Template) == 0 && len(o.TemplateFile) == 0 {
+			return fmt.Errorf("no output template or template file provided")
+		}
+
+		if len(o.Template) > 0 && len(o.TemplateFile) > 0 {
+			return fmt.Errorf("output template and template file are mutually exclusive")
+		}
+	}
+
+	// check if a timeline is requested
+	if o.Timeline {
+		// verify the format supports the timeline
+		if o.Format != formatMarkdown && o.Format != formatTable {
+			return fmt.Errorf("invalid output format provided for timeline: %s", o.Format)
+		}
+
+		// verify the timeline has room for the bars
+		if o.TimelineWidth < timelineMaxLabel+timelineDurationWidth+timelineMinWidth {
+			return fmt.Errorf("invalid output timeline width provided: %d", o.TimelineWidth)
+		}
+	}
+
+	// check if a collector endpoint is provided
+	if len(o.Endpoint) > 0 {
+		// verify the format supports the collector endpoint
+		if o.Format != formatOTLP {
+			return fmt.Errorf("invalid output format provided for endpoint: %s", o.Format)
+		}
+
+		// check to make sure it's a valid url
+		u, err := url.Parse(o.Endpoint)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("invalid output endpoint provided: %s", o.Endpoint)
+		}
+	}
+
+	// check if a Pushgateway is provided
+	if len(o.Pushgateway) > 0 {
+		// verify the format supports the Pushgateway
+		if o.Format != formatPrometheus {
+			return fmt.Errorf("invalid output format provided for pushgateway: %s", o.Format)
+		}
+
+		// check to make sure it's a valid url
+		u, err := url.Parse(o.Pushgateway)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("invalid output pushgateway provided: %s", o.Pushgateway)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutput_Write(t *testing.T) {
+	// setup types
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics", "build.prom")
+
+	// create a previous file to be replaced with the build summary
+	err := os.MkdirAll(filepath.Dir(path), 0750)
+	if err != nil {
+		t.Fatalf("unable to create directory: %v", err)
+	}
+
+	err = os.WriteFile(path, []byte("stale"), 0600)
+	if err != nil {
+		t.Fatalf("unable to write file: %v", err)
+	}
+
+	o := &Output{Format: formatPrometheus, Path: path}
+
+	err = o.Write(context.Background(), testBuildSummary())
+	if err != nil {
+		t.Fatalf("Write returned err: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+
+	if !strings.HasPrefix(string(got), "# HELP vela_build_duration_seconds") {
+		t.Errorf("Write is %s, want Prometheus metrics", got)
+	}
+
+	// verify the temporary file was renamed into place
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("unable to read directory: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("Write left %d files in the directory, want 1", len(entries))
+	}
+}
+
+func TestOutput_Write_Failure(t *testing.T) {
+	// setup types
+	dir := t.TempDir()
+	path := filepath.Join(dir, "build.txt")
+
+	o := &Output{Format: formatTemplate, Template: "{{ .Missing }}", Path: path}
+
+	err := o.Write(context.Background(), testBuildSummary())
+	if err == nil {
+		t.Errorf("Write should have returned err")
+	}
+
+	// verify the temporary file was removed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unable to read directory: %v", err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("Write left %d files in the directory, want 0", len(entries))
+	}
+}
@@ -3,84 +3,332 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/go-vela/sdk-go/vela"
+	api "github.com/go-vela/server/api/types"
 )
 
 // Plugin represents the configuration loaded for the plugin.
 type Plugin struct {
 	// build arguments loaded for the plugin
 	Build *Build
+	// compare arguments loaded for the plugin
+	Compare *Compare
 	// config arguments loaded for the plugin
 	Config *Config
+	// diff arguments loaded for the plugin
+	Diff *Diff
+	// output arguments loaded for the plugin
+	Outputs []*Output
 	// repo arguments loaded for the plugin
 	Repo *Repo
+	// wait arguments loaded for the plugin
+	Wait *Wait
 }
 
 // Exec formats and runs the commands for creating a summary of the build.
-func (p *Plugin) Exec() error {
+func (p *Plugin) Exec(ctx context.Context) error {
 	logrus.Debug("running plugin with provided configuration")
 
 	logrus.Infof("creating client for %s", p.Config.Server)
 	// create new Vela client from config configuration
-	client, err := p.Config.New()
+	client, err := p.Config.New(ctx)
 	if err != nil {
 		return err
 	}
 
-	logrus.Infof("capturing build %s/%s/%d", p.Repo.Org, p.Repo.Name, p.Build.Number)
-	// send API call to capture a build
-	//
-	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#BuildService.Get
-	build, _, err := client.Build.Get(p.Repo.Org, p.Repo.Name, p.Build.Number)
+	// capture the build summary shared by all outputs
+	s, err := p.summary(ctx, client)
 	if err != nil {
 		return err
 	}
 
-	// set the pagination options for list of resources
+	// create a variable to track the errors from the outputs
+	var errs []error
+
+	// write the build summary to each output
 	//
-	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#ListOptions
-	opts := &vela.ListOptions{
-		PerPage: 100,
+	// a failure for one output doesn't prevent writing to the others
+	for _, output := range p.Outputs {
+		err = output.Write(ctx, s)
+		if err != nil {
+			logrus.Errorf("unable to write %s output for build summary: %v", output.Format, err)
+
+			errs = append(errs, fmt.Errorf("%s output: %w", output.Format, err))
+		}
 	}
 
-	logrus.Infof("capturing services for build %s/%s/%d", p.Repo.Org, p.Repo.Name, p.Build.Number)
-	// send API call to capture a list of services
-	//
-	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#SvcService.GetAll
-	services, _, err := client.Svc.GetAll(p.Repo.Org, p.Repo.Name, p.Build.Number, opts)
+	return errors.Join(errs...)
+}
+
+// summary is a helper function to capture the build summary for the
+// build running the plugin, or the comparison of the builds provided
+// to the diff command.
+func (p *Plugin) summary(ctx context.Context, client *vela.Client) (*Summary, error) {
+	// check if the plugin is comparing two builds
+	if p.Diff != nil {
+		return p.diff(ctx, client)
+	}
+
+	// check if the plugin should wait for the build to complete
+	if p.Wait.Enabled {
+		err := p.wait(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// capture the build summary for the build
+	s, err := p.capture(ctx, client, p.Repo, p.Build.Number)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	logrus.Infof("capturing steps for build %s/%s/%d", p.Repo.Org, p.Repo.Name, p.Build.Number)
-	// send API call to capture a list of steps
-	//
-	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#StepService.GetAll
-	steps, _, err := client.Step.GetAll(p.Repo.Org, p.Repo.Name, p.Build.Number, opts)
+	// check if the build should be compared against the previous build
+	if p.Compare.Enabled {
+		p.compare(ctx, client, s)
+	}
+
+	return s, nil
+}
+
+// capture is a helper function to capture the build, services, steps
+// and logs for the provided build from the Vela server and create the
+// build summary.
+//
+// The API calls are sent concurrently, bounded by the concurrency
+// in the config configuration. If any of the API calls fail, the
+// remaining API calls are canceled and all failures are returned.
+func (p *Plugin) capture(ctx context.Context, client *vela.Client, repo *Repo, number int) (*Summary, error) {
+	logrus.Infof("capturing build %s/%s/%d", repo.Org, repo.Name, number)
+
+	var (
+		build    *api.Build
+		services *[]api.Service
+		steps    *[]api.Step
+	)
+
+	// create the group for sending the API calls concurrently
+	f := newFetcher(ctx, p.Config.Concurrency)
+
+	f.Go(func() error {
+		// send API call to capture a build
+		//
+		// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#BuildService.Get
+		return f.call(func() (err error) {
+			build, _, err = client.Build.Get(repo.Org, repo.Name, number)
+			if err != nil {
+				return fmt.Errorf("unable to capture build: %w", err)
+			}
+
+			return nil
+		})
+	})
+
+	f.Go(func() (err error) {
+		// send API calls to capture a list of services
+		//
+		// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#SvcService.GetAll
+		services, err = paginate(f, "services", func(opts *vela.ListOptions) (*[]api.Service, *vela.Response, error) {
+			return client.Svc.GetAll(repo.Org, repo.Name, number, opts)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to capture services: %w", err)
+		}
+
+		return nil
+	})
+
+	f.Go(func() (err error) {
+		// send API calls to capture a list of steps
+		//
+		// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#StepService.GetAll
+		steps, err = paginate(f, "steps", func(opts *vela.ListOptions) (*[]api.Step, *vela.Response, error) {
+			return client.Step.GetAll(repo.Org, repo.Name, number, opts)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to capture steps: %w", err)
+		}
+
+		return nil
+	})
+
+	// wait for all API calls to complete
+	err := f.Wait()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	logrus.Infof("capturing logs for build %s/%s/%d", p.Repo.Org, p.Repo.Name, p.Build.Number)
-	// send API call to capture a list of build logs
+	// capture the step running the plugin
+	self := p.self(repo, number, steps)
+
+	// check if the step running the plugin should be excluded
 	//
-	// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#BuildService.GetLogs
-	logs, _, err := client.Build.GetLogs(p.Repo.Org, p.Repo.Name, p.Build.Number, nil)
+	// the step can't complete until after the summary is created
+	if p.Wait.Enabled && self != nil {
+		filtered := []api.Step{}
+
+		for _, s := range *steps {
+			if s.GetNumber() == self.GetNumber() {
+				logrus.Debugf("excluding step %s running the plugin from build summary", s.GetName())
+
+				continue
+			}
+
+			filtered = append(filtered, s)
+		}
+
+		steps = &filtered
+	}
+
+	// capture the logs for the services and steps
+	logs, err := p.logs(ctx, client, repo, number, services, steps)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return table(build, logs, services, steps)
+	// capture the stage needs for analyzing the critical path
+	needs := p.stageNeeds(client, repo, build, steps)
+
+	summary := newSummary(build, logs, needs, services, steps)
+
+	// track the step running the plugin to exclude it from comparisons
+	summary.Plugin = self
+
+	return summary, nil
+}
+
+// self is a helper function to capture the step running the plugin
+// from the provided steps if they're for the build running the plugin.
+//
+// A nil step is returned for any other build.
+func (p *Plugin) self(repo *Repo, number int, steps *[]api.Step) *api.Step {
+	// check if the steps are for the build running the plugin
+	if p.Build.Step == 0 || number != p.Build.Number || repo.Org != p.Repo.Org || repo.Name != p.Repo.Name {
+		return nil
+	}
+
+	for _, s := range *steps {
+		if s.GetNumber() == p.Build.Step {
+			return &s
+		}
+	}
+
+	return nil
+}
+
+// logs is a helper function to stream the logs for each
+// service and step from the Vela server into a log index.
+//
+// Every page of logs for the build is streamed in order, so a resource
+// with more than one log entry has the data for all of its entries
+// analyzed in order. The logs are analyzed as they're streamed, so the
+// memory used is bounded by the log memory in the config configuration
+// rather than the size of the logs for the build.
+func (p *Plugin) logs(ctx context.Context, client *vela.Client, repo *Repo, number int, services *[]api.Service, steps *[]api.Step) (*logIndex, error) {
+	logrus.Infof("capturing logs for build %s/%s/%d", repo.Org, repo.Name, number)
+
+	// create a variable to track the services and steps that started running
+	count := 0
+
+	for _, s := range *services {
+		if s.GetStarted() > 0 {
+			count++
+		}
+	}
+
+	for _, s := range *steps {
+		if s.GetStarted() > 0 {
+			count++
+		}
+	}
+
+	// create the index splitting the memory across the services and steps,
+	// since the line being read and the excerpt are kept for each of them
+	index := newLogIndex(int(p.Config.LogMemory / uint64(max(count, 1))))
+
+	// create the metrics for each service that started running
+	for _, s := range *services {
+		if s.GetStarted() > 0 {
+			index.Service(s.GetID())
+		}
+	}
+
+	// create the metrics for each step that started running
+	for _, s := range *steps {
+		if s.GetStarted() > 0 {
+			index.Step(s.GetID())
+		}
+	}
+
+	// stream every page of logs for the build
+	for page := 1; ; {
+		// check if the plugin was canceled
+		err := ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		logrus.Tracef("capturing page %d of logs", page)
+
+		path := fmt.Sprintf("/api/v1/repos/%s/%s/builds/%d/logs?page=%d&per_page=%d", repo.Org, repo.Name, number, page, perPage)
+
+		resp, err := logStream(client, path, index)
+		if err != nil {
+			// check if the first page of logs failed
+			if page == 1 {
+				return nil, fmt.Errorf("unable to capture logs: %w", err)
+			}
+
+			logrus.Warnf("unable to capture page %d of logs, build summary may be incomplete: %v", page, err)
+
+			break
+		}
+
+		// check if the next page moves forward
+		next := linkPage(resp, "next")
+		if next <= page {
+			break
+		}
+
+		page = next
+	}
+
+	return index, index.Close()
 }
 
 // Validate verifies the plugin is properly configured.
 func (p *Plugin) Validate() error {
 	logrus.Debug("validating plugin configuration")
 
-	// validate build configuration
-	err := p.Build.Validate()
+	// check if the plugin is comparing two builds
+	if p.Diff != nil {
+		// validate diff configuration
+		err := p.Diff.Validate()
+		if err != nil {
+			return err
+		}
+	} else {
+		// validate build configuration
+		err := p.Build.Validate()
+		if err != nil {
+			return err
+		}
+
+		// validate repo configuration
+		err = p.Repo.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	// validate compare configuration
+	err := p.Compare.Validate()
 	if err != nil {
 		return err
 	}
@@ -91,11 +339,31 @@ func (p *Plugin) Validate() error {
 		return err
 	}
 
-	// validate repo configuration
-	err = p.Repo.Validate()
+	// check if any outputs were provided
+	if len(p.Outputs) == 0 {
+		return fmt.Errorf("no outputs provided")
+	}
+
+	// validate output configuration
+	for _, output := range p.Outputs {
+		err = output.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	// validate wait configuration
+	err = p.Wait.Validate()
 	if err != nil {
 		return err
 	}
 
+	// verify the step running the plugin is provided when waiting
+	//
+	// otherwise the plugin waits for its own step until the timeout
+	if p.Wait.Enabled && p.Build.Step == 0 {
+		return fmt.Errorf("no build step provided for waiting")
+	}
+
 	return nil
 }
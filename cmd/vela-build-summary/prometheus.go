@@ -0,0 +1,295 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+// prometheusJob represents the job name used for
+// the grouping key when pushing to a Pushgateway.
+const prometheusJob = "vela-build-summary"
+
+// prometheusStatuses represents the list of statuses
+// reported for the status gauge of each resource.
+var prometheusStatuses = []string{
+	constants.StatusPending,
+	constants.StatusRunning,
+	constants.StatusSuccess,
+	constants.StatusFailure,
+	constants.StatusKilled,
+	constants.StatusCanceled,
+	constants.StatusError,
+	constants.StatusSkipped,
+}
+
+// prometheusMetric represents a metric reported for each
+// resource in the Prometheus exposition format.
+type prometheusMetric struct {
+	// name of the metric without the prefix
+	Name string
+	// help text describing the metric
+	Help string
+	// function to capture the value of the metric for a resource
+	Value func(r *Resource) float64
+}
+
+// prometheusMetrics represents the list of metrics
+// reported for each resource in the build summary.
+var prometheusMetrics = []*prometheusMetric{
+	{
+		Name:  "duration_seconds",
+		Help:  "duration the %s ran for in seconds",
+		Value: func(r *Resource) float64 { return r.Duration.Seconds() },
+	},
+	{
+		Name:  "queue_seconds",
+		Help:  "duration the %s waited before it started in seconds",
+		Value: func(r *Resource) float64 { return r.Queued().Seconds() },
+	},
+	{
+		Name:  "log_bytes",
+		Help:  "size of logs the %s produced in bytes",
+		Value: func(r *Resource) float64 { return float64(r.Size) },
+	},
+	{
+		Name:  "log_errors",
+		Help:  "lines of logs the %s produced reporting an error",
+		Value: func(r *Resource) float64 { return float64(r.Errors) },
+	},
+	{
+		Name:  "log_lines",
+		Help:  "lines of logs the %s produced",
+		Value: func(r *Resource) float64 { return float64(r.Lines) },
+	},
+}
+
+// prometheusEscape is a helper function to escape
+// a label value in the Prometheus exposition format.
+func prometheusEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// prometheusLabels is a helper function to produce the labels
+// for a resource in the Prometheus exposition format.
+func prometheusLabels(s *Summary, r *Resource) string {
+	// set of labels included for every resource
+	labels := fmt.Sprintf(`org="%s",repo="%s",build="%d"`,
+		prometheusEscape(s.Build.GetRepo().GetOrg()),
+		prometheusEscape(s.Build.GetRepo().GetName()),
+		s.Totals.Number,
+	)
+
+	switch r.Type {
+	case "service":
+		labels += fmt.Sprintf(`,service="%s"`, prometheusEscape(r.Name))
+	case "step":
+		labels += fmt.Sprintf(`,step="%s",stage="%s"`, prometheusEscape(r.Name), prometheusEscape(r.Stage))
+	}
+
+	return labels
+}
+
+// prometheusFamily is a helper function to produce the metric
+// families for a type of resource in the build summary.
+func prometheusFamily(buf *bytes.Buffer, s *Summary, kind string, resources []*Resource) {
+	// check if any resources exist for the family
+	if len(resources) == 0 {
+		return
+	}
+
+	logrus.Tracef("adding %s metrics to Prometheus document", kind)
+
+	// add the metrics for each resource
+	for _, m := range prometheusMetrics {
+		name := fmt.Sprintf("vela_%s_%s", kind, m.Name)
+
+		fmt.Fprintf(buf, "# HELP %s %s\n", name, fmt.Sprintf(m.Help, kind))
+		fmt.Fprintf(buf, "# TYPE %s gauge\n", name)
+
+		for _, r := range resources {
+			fmt.Fprintf(buf, "%s{%s} %s\n", name, prometheusLabels(s, r), strconv.FormatFloat(m.Value(r), 'g', -1, 64))
+		}
+	}
+
+	// add the metrics from the previous build for each resource
+	prometheusCompare(buf, s, kind, resources)
+
+	// add the status for each resource
+	name := fmt.Sprintf("vela_%s_status", kind)
+
+	fmt.Fprintf(buf, "# HELP %s status of the %s set to 1 for the current status\n", name, kind)
+	fmt.Fprintf(buf, "# TYPE %s gauge\n", name)
+
+	for _, r := range resources {
+		for _, status := range prometheusStatuses {
+			var value int
+			if r.Status == status {
+				value = 1
+			}
+
+			fmt.Fprintf(buf, "%s{%s,status=\"%s\"} %d\n", name, prometheusLabels(s, r), status, value)
+		}
+	}
+}
+
+// prometheusCompare is a helper function to produce the metric families
+// with the values from the previous build for a type of resource, and if
+// each metric compared regressed from the previous build.
+//
+// Only the resources that ran in the previous build are included, with
+// the number of the previous build as an additional label.
+func prometheusCompare(buf *bytes.Buffer, s *Summary, kind string, resources []*Resource) {
+	// check if the build was compared against a previous build
+	if s.Previous == nil {
+		return
+	}
+
+	// capture the resources that ran in the previous build
+	var compared []*Resource
+
+	for _, r := range resources {
+		if r.Previous != nil {
+			compared = append(compared, r)
+		}
+	}
+
+	// check if any resources ran in the previous build
+	if len(compared) == 0 {
+		return
+	}
+
+	logrus.Tracef("adding %s comparison metrics to Prometheus document", kind)
+
+	// create a function to produce the labels for a resource
+	labels := func(r *Resource) string {
+		return fmt.Sprintf(`%s,previous_build="%d"`, prometheusLabels(s, r), s.Previous.Totals.Number)
+	}
+
+	// add the metrics from the previous build for each resource
+	for _, m := range prometheusMetrics {
+		name := fmt.Sprintf("vela_%s_previous_%s", kind, m.Name)
+
+		fmt.Fprintf(buf, "# HELP %s %s in the previous build\n", name, fmt.Sprintf(m.Help, kind))
+		fmt.Fprintf(buf, "# TYPE %s gauge\n", name)
+
+		for _, r := range compared {
+			fmt.Fprintf(buf, "%s{%s} %s\n", name, labels(r), strconv.FormatFloat(m.Value(r.Previous), 'g', -1, 64))
+		}
+	}
+
+	// add if each metric regressed for each resource
+	name := fmt.Sprintf("vela_%s_regression", kind)
+
+	fmt.Fprintf(buf, "# HELP %s metric of the %s set to 1 if it regressed from the previous build\n", name, kind)
+	fmt.Fprintf(buf, "# TYPE %s gauge\n", name)
+
+	for _, r := range compared {
+		for _, metric := range []string{"duration", "log_lines", "log_bytes"} {
+			var value int
+
+			if slices.Contains(r.Regressions, metric) {
+				value = 1
+			}
+
+			fmt.Fprintf(buf, "%s{%s,metric=\"%s\"} %d\n", name, labels(r), metric, value)
+		}
+	}
+}
+
+// prometheusOutput is a helper function to output the provided build
+// summary as metrics in the Prometheus exposition format.
+//
+// The metrics include the duration, queue time, log size, log lines and
+// status for the build, services and steps. The output is suitable for
+// the textfile collector of the Prometheus node exporter.
+//
+// If the build was compared against a previous build, the metrics from
+// the previous build and the regressions are included for each resource.
+func prometheusOutput(w io.Writer, s *Summary) error {
+	logrus.Debug("creating Prometheus metrics for build summary")
+
+	// create a buffer to render the metrics in
+	buf := new(bytes.Buffer)
+
+	// add the metrics for the build
+	prometheusFamily(buf, s, "build", []*Resource{s.Totals})
+
+	// add the metrics for the services
+	prometheusFamily(buf, s, "service", s.Services)
+
+	// add the metrics for the steps
+	prometheusFamily(buf, s, "step", s.Steps)
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
+
+// prometheusPush is a helper function to push the provided
+// build summary as metrics to a Prometheus Pushgateway.
+//
+// The metrics are grouped by the org and repo for the build,
+// so each push replaces the metrics from the previous build
+// rather than accumulating a group for every build.
+func prometheusPush(ctx context.Context, gateway string, s *Summary) error {
+	// create the URL for the grouping key of the metrics
+	//
+	// https://github.com/prometheus/pushgateway#url
+	u := fmt.Sprintf("%s/metrics/job/%s/org/%s/repo/%s",
+		strings.TrimSuffix(gateway, "/"),
+		prometheusJob,
+		url.PathEscape(s.Build.GetRepo().GetOrg()),
+		url.PathEscape(s.Build.GetRepo().GetName()),
+	)
+
+	logrus.Infof("pushing Prometheus metrics for build summary to %s", u)
+
+	// create a buffer to render the metrics in
+	buf := new(bytes.Buffer)
+
+	err := prometheusOutput(buf, s)
+	if err != nil {
+		return err
+	}
+
+	// create the request to replace the metrics for the grouping key
+	//
+	// https://pkg.go.dev/net/http#NewRequestWithContext
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, buf)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	// send the request to the Pushgateway
+	//
+	// https://pkg.go.dev/net/http#Client.Do
+	resp, err := outputClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// check if the Pushgateway accepted the metrics
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("unable to push metrics to %s: %s: %s", gateway, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
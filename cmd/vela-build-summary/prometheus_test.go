@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusEscape(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "plain",
+			s:    "build",
+			want: "build",
+		},
+		{
+			name: "special characters",
+			s:    "say \"hi\"\\\nbye",
+			want: `say \"hi\"\\\nbye`,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := prometheusEscape(test.s)
+
+			if got != test.want {
+				t.Errorf("prometheusEscape is %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPrometheusOutput(t *testing.T) {
+	// setup types
+	s := testBuildSummary()
+
+	want := `# HELP vela_build_duration_seconds duration the build ran for in seconds
+# TYPE vela_build_duration_seconds gauge
+vela_build_duration_seconds{org="foo",repo="bar",build="1"} 60
+# HELP vela_build_queue_seconds duration the build waited before it started in seconds
+# TYPE vela_build_queue_seconds gauge
+vela_build_queue_seconds{org="foo",repo="bar",build="1"} 8
+# HELP vela_build_log_bytes size of logs the build produced in bytes
+# TYPE vela_build_log_bytes gauge
+vela_build_log_bytes{org="foo",repo="bar",build="1"} 93
+# HELP vela_build_log_errors lines of logs the build produced reporting an error
+# TYPE vela_build_log_errors gauge
+vela_build_log_errors{org="foo",repo="bar",build="1"} 1
+# HELP vela_build_log_lines lines of logs the build produced
+# TYPE vela_build_log_lines gauge
+vela_build_log_lines{org="foo",repo="bar",build="1"} 5
+# HELP vela_build_status status of the build set to 1 for the current status
+# TYPE vela_build_status gauge
+vela_build_status{org="foo",repo="bar",build="1",status="pending"} 0
+vela_build_status{org="foo",repo="bar",build="1",status="running"} 0
+vela_build_status{org="foo",repo="bar",build="1",status="success"} 0
+vela_build_status{org="foo",repo="bar",build="1",status="failure"} 1
+vela_build_status{org="foo",repo="bar",build="1",status="killed"} 0
+vela_build_status{org="foo",repo="bar",build="1",status="canceled"} 0
+vela_build_status{org="foo",repo="bar",build="1",status="error"} 0
+vela_build_status{org="foo",repo="bar",build="1",status="skipped"} 0
+# HELP vela_service_duration_seconds duration the service ran for in seconds
+# TYPE vela_service_duration_seconds gauge
+vela_service_duration_seconds{org="foo",repo="bar",build="1",service="postgres"} 60
+# HELP vela_service_queue_seconds duration the service waited before it started in seconds
+# TYPE vela_service_queue_seconds gauge
+vela_service_queue_seconds{org="foo",repo="bar",build="1",service="postgres"} 10
+# HELP vela_service_log_bytes size of logs the service produced in bytes
+# TYPE vela_service_log_bytes gauge
+vela_service_log_bytes{org="foo",repo="bar",build="1",service="postgres"} 23
+# HELP vela_service_log_errors lines of logs the service produced reporting an error
+# TYPE vela_service_log_errors gauge
+vela_service_log_errors{org="foo",repo="bar",build="1",service="postgres"} 0
+# HELP vela_service_log_lines lines of logs the service produced
+# TYPE vela_service_log_lines gauge
+vela_service_log_lines{org="foo",repo="bar",build="1",service="postgres"} 1
+# HELP vela_service_status status of the service set to 1 for the current status
+# TYPE vela_service_status gauge
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="pending"} 0
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="running"} 0
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="success"} 1
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="failure"} 0
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="killed"} 0
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="canceled"} 0
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="error"} 0
+vela_service_status{org="foo",repo="bar",build="1",service="postgres",status="skipped"} 0
+# HELP vela_step_duration_seconds duration the step ran for in seconds
+# TYPE vela_step_duration_seconds gauge
+vela_step_duration_seconds{org="foo",repo="bar",build="1",step="clone",stage="init"} 10
+vela_step_duration_seconds{org="foo",repo="bar",build="1",step="test",stage="test"} 40
+vela_step_duration_seconds{org="foo",repo="bar",build="1",step="publish",stage="publish"} 0
+# HELP vela_step_queue_seconds duration the step waited before it started in seconds
+# TYPE vela_step_queue_seconds gauge
+vela_step_queue_seconds{org="foo",repo="bar",build="1",step="clone",stage="init"} 10
+vela_step_queue_seconds{org="foo",repo="bar",build="1",step="test",stage="test"} 20
+vela_step_queue_seconds{org="foo",repo="bar",build="1",step="publish",stage="publish"} 0
+# HELP vela_step_log_bytes size of logs the step produced in bytes
+# TYPE vela_step_log_bytes gauge
+vela_step_log_bytes{org="foo",repo="bar",build="1",step="clone",stage="init"} 37
+vela_step_log_bytes{org="foo",repo="bar",build="1",step="test",stage="test"} 33
+vela_step_log_bytes{org="foo",repo="bar",build="1",step="publish",stage="publish"} 0
+# HELP vela_step_log_errors lines of logs the step produced reporting an error
+# TYPE vela_step_log_errors gauge
+vela_step_log_errors{org="foo",repo="bar",build="1",step="clone",stage="init"} 0
+vela_step_log_errors{org="foo",repo="bar",build="1",step="test",stage="test"} 1
+vela_step_log_errors{org="foo",repo="bar",build="1",step="publish",stage="publish"} 0
+# HELP vela_step_log_lines lines of logs the step produced
+# TYPE vela_step_log_lines gauge
+vela_step_log_lines{org="foo",repo="bar",build="1",step="clone",stage="init"} 2
+vela_step_log_lines{org="foo",repo="bar",build="1",step="test",stage="test"} 2
+vela_step_log_lines{org="foo",repo="bar",build="1",step="publish",stage="publish"} 0
+# HELP vela_step_status status of the step set to 1 for the current status
+# TYPE vela_step_status gauge
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="pending"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="running"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="success"} 1
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="failure"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="killed"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="canceled"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="error"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="clone",stage="init",status="skipped"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="pending"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="running"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="success"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="failure"} 1
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="killed"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="canceled"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="error"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="test",stage="test",status="skipped"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="pending"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="running"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="success"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="failure"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="killed"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="canceled"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="error"} 0
+vela_step_status{org="foo",repo="bar",build="1",step="publish",stage="publish",status="skipped"} 1
+`
+
+	buf := new(bytes.Buffer)
+
+	err := prometheusOutput(buf, s)
+	if err != nil {
+		t.Fatalf("prometheusOutput returned err: %v", err)
+	}
+
+	if buf.String() != want {
+		t.Errorf("prometheusOutput is %s, want %s", buf.String(), want)
+	}
+}
+
+func TestPrometheusPush(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		status  int
+		failure bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+		},
+		{
+			name:    "rejected",
+			status:  http.StatusBadRequest,
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("request method is %s, want %s", r.Method, http.MethodPut)
+				}
+
+				if r.URL.Path != "/metrics/job/vela-build-summary/org/foo/repo/bar" {
+					t.Errorf("request path is %s, want the grouping key for foo/bar", r.URL.Path)
+				}
+
+				body, _ := io.ReadAll(r.Body)
+				if !strings.Contains(string(body), `vela_build_duration_seconds{org="foo",repo="bar",build="1"} 60`) {
+					t.Errorf("request body is missing the build duration: %s", body)
+				}
+
+				w.WriteHeader(test.status)
+			}))
+			defer srv.Close()
+
+			err := prometheusPush(context.Background(), srv.URL+"/", testBuildSummary())
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("prometheusPush should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("prometheusPush returned err: %v", err)
+			}
+		})
+	}
+}
@@ -4,8 +4,6 @@
 package main
 
 import (
-	"bytes"
-	"fmt"
 	"sort"
 	"time"
 
@@ -16,29 +14,31 @@ import (
 	api "github.com/go-vela/server/api/types"
 )
 
-// serviceLines is a helper function to calculate the total lines of logs
-// a service produced by measuring the newlines (\n) in that log entry.
-func serviceLines(s *api.Service, logs *[]api.Log) int {
-	logrus.Debugf("calculating lines of logs for service %s for build summary table", s.GetName())
+// serviceExcerpt is a helper function to capture the last
+// lines of logs a service produced from the log index.
+func serviceExcerpt(s *api.Service, logs *logIndex) string {
+	logrus.Debugf("capturing excerpt of logs for service %s for build summary", s.GetName())
 
-	// create a variable to track the lines of logs for the service
-	var lines int
+	// capture the excerpt for the logs
+	return logs.Service(s.GetID()).Excerpt()
+}
 
-	// iterate through all logs in the list
-	for _, log := range *logs {
-		// check if the log service ID matches the service ID
-		if log.GetServiceID() != s.GetID() {
-			continue
-		}
+// serviceErrors is a helper function to capture the total lines of logs
+// a service produced reporting an error from the log index.
+func serviceErrors(s *api.Service, logs *logIndex) int {
+	logrus.Debugf("capturing errors in logs for service %s for build summary", s.GetName())
 
-		// capture the total lines for the logs
-		lines = bytes.Count(log.GetData(), []byte("\n"))
+	// capture the total errors for the logs
+	return logs.Service(s.GetID()).Errors
+}
 
-		// break out of the for loop
-		break
-	}
+// serviceLines is a helper function to capture the total lines of logs
+// a service produced by measuring the newlines (\n) in the log index.
+func serviceLines(s *api.Service, logs *logIndex) int {
+	logrus.Debugf("calculating lines of logs for service %s for build summary table", s.GetName())
 
-	return lines
+	// capture the total lines for the logs
+	return logs.Service(s.GetID()).Lines
 }
 
 // serviceRate is a helper function to calculate the total size of logs
@@ -50,6 +50,14 @@ func serviceRate(duration string, size uint64) int64 {
 	// calculate the timestamp duration in seconds
 	s := (float64(d) / float64(time.Second))
 
+	// check if the service ran for any duration
+	//
+	// this avoids dividing by zero for a service that hasn't
+	// started running or finished in less than a second
+	if s <= 0 {
+		return 0
+	}
+
 	// return the rate of bytes per second
 	return int64(float64(size) / s)
 }
@@ -68,63 +76,27 @@ func serviceReverse(s []api.Service) []api.Service {
 }
 
 // serviceRows is a helper function to produce service rows in the build summary table.
-func serviceRows(table *uitable.Table, logs *[]api.Log, services *[]api.Service, buildLines *int, buildSize *uint64) {
+//
+// If compare is enabled, the values from and change from the previous build are added to each row.
+func serviceRows(table *uitable.Table, services []*Resource, compare bool) {
 	logrus.Debug("adding service information to build summary table")
 
 	// iterate through all services in the list
-	for _, s := range serviceReverse(*services) {
-		logrus.Tracef("adding service %s to build summary table", s.GetName())
-
-		// calculate lines based off the service logs
-		//
-
-		lines := serviceLines(&s, logs)
-
-		// calculate size based off the service logs
-		//
-
-		size := serviceSize(&s, logs)
-
-		// calculate duration based off the service timestamps
-		duration := s.Duration()
-
-		// calculate rate based off service duration and size
-		rate := fmt.Sprintf("%d B/s", serviceRate(duration, size))
-
-		// update the lines of build logs with the lines of service logs
-		*buildLines = *buildLines + lines
-
-		// update the size of the build logs with the size of the service logs
-		*buildSize = *buildSize + size
+	for _, r := range services {
+		logrus.Tracef("adding service %s to build summary table", r.Name)
 
 		// add a row to the table with the specified values
 		//
 		// https://pkg.go.dev/github.com/gosuri/uitable?tab=doc#Table.AddRow
-		table.AddRow("service", s.GetName(), s.GetNumber(), s.GetStatus(), duration, lines, humanize.Bytes(size), rate)
+		table.AddRow(append([]any{"service", r.Name, r.Number, r.Status, r.Wait(), r.Elapsed(), r.Lines, humanize.Bytes(r.Size), r.Throughput(), r.Leeway()}, compareCells(r, compare)...)...)
 	}
 }
 
-// serviceSize is a helper function to calculate the total size of logs
-// a service produced by measuring the data in that log entry.
-func serviceSize(s *api.Service, logs *[]api.Log) uint64 {
+// serviceSize is a helper function to capture the total size of logs
+// a service produced by measuring the data in the log index.
+func serviceSize(s *api.Service, logs *logIndex) uint64 {
 	logrus.Debugf("calculating size of logs for service %s for build summary table", s.GetName())
 
-	// create a variable to track the size of logs for the service
-	var size uint64
-
-	// iterate through all logs in the list
-	for _, log := range *logs {
-		// check if the log service ID matches the service ID
-		if log.GetServiceID() != s.GetID() {
-			continue
-		}
-
-		// capture the total size for the logs
-		size = uint64(len(log.GetData()))
-
-		// break out of the for loop
-		break
-	}
-
-	return size
+	// capture the total size for the logs
+	return logs.Service(s.GetID()).Size
 }
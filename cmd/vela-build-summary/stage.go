@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gosuri/uitable"
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+// Stage represents the metrics captured for a stage of steps in a build.
+type Stage struct {
+	// name of the stage
+	Name string
+	// metrics captured for the stage as a whole
+	//
+	// the duration is the wall-clock span from when the
+	// first step started until the last step finished
+	Totals *Resource
+	// summed duration of the steps in the stage
+	Time time.Duration
+	// metrics captured for each step in the stage
+	Steps []*Resource
+	// number of steps excluded from the totals
+	Excluded int
+}
+
+// stageStatuses represents the order of precedence for the
+// status of a stage based off the status of its steps.
+var stageStatuses = []string{
+	constants.StatusFailure,
+	constants.StatusError,
+	constants.StatusKilled,
+	constants.StatusCanceled,
+	constants.StatusRunning,
+	constants.StatusPending,
+	constants.StatusPendingApproval,
+	constants.StatusSuccess,
+	constants.StatusSkipped,
+}
+
+// stageStatus is a helper function to capture the status for a
+// stage from the status with the highest precedence for its steps.
+func stageStatus(steps []*Resource) string {
+	// create a variable to track the precedence for the status
+	rank := len(stageStatuses)
+
+	for _, r := range steps {
+		i := slices.Index(stageStatuses, r.Status)
+		if i >= 0 && i < rank {
+			rank = i
+		}
+	}
+
+	// check if none of the steps have a known status
+	if rank == len(stageStatuses) {
+		return constants.StatusPending
+	}
+
+	return stageStatuses[rank]
+}
+
+// newStage is a helper function to capture the metrics
+// for a stage from the metrics for its steps.
+//
+// Like the totals for the build, only complete steps are
+// included in the log lines and log size for the stage.
+func newStage(name string, steps []*Resource) *Stage {
+	logrus.Tracef("capturing metrics for stage %s for build summary", name)
+
+	stage := &Stage{
+		Name:  name,
+		Steps: steps,
+		Totals: &Resource{
+			Type:   "stage",
+			Name:   name,
+			Stage:  name,
+			Status: stageStatus(steps),
+		},
+	}
+
+	// create variables to track when the stage started and finished
+	var (
+		end     int64
+		running bool
+	)
+
+	for _, r := range steps {
+		stage.Time += r.Duration
+
+		// check if the step started running
+		if r.Started == 0 {
+			continue
+		}
+
+		// track when the first step started
+		if stage.Totals.Started == 0 || r.Started < stage.Totals.Started {
+			stage.Totals.Started = r.Started
+		}
+
+		// track when the last step finished, or how long
+		// a step that is still running has run for so far
+		end = max(end, finish(r))
+
+		// check if the step is still running
+		if r.Running() {
+			running = true
+		}
+
+		// check if the step is complete
+		if !r.Complete() {
+			stage.Excluded++
+
+			continue
+		}
+
+		stage.Totals.Lines += r.Lines
+		stage.Totals.Size += r.Size
+		stage.Totals.Errors += r.Errors
+	}
+
+	// check if the stage started running
+	if stage.Totals.Started == 0 {
+		return stage
+	}
+
+	// check if the stage is complete
+	if !running {
+		stage.Totals.Finished = end
+	}
+
+	stage.Totals.Duration = time.Duration(end-stage.Totals.Started) * time.Second
+
+	// calculate rate based off stage duration and size
+	stage.Totals.Rate = buildRate(stage.Totals.Duration.String(), stage.Totals.Size)
+
+	return stage
+}
+
+// newStages is a helper function to group the provided steps by stage.
+//
+// The stages are ordered by the lowest step number in each stage, and
+// nil is returned if none of the steps ran in a stage, since pipelines
+// using steps rather than stages are displayed as a list of steps.
+func newStages(steps []*Resource) []*Stage {
+	logrus.Debug("grouping steps by stage for build summary")
+
+	// create variables to track the steps for each stage
+	var (
+		names  []string
+		groups = make(map[string][]*Resource)
+	)
+
+	// iterate through all steps in the list
+	//
+	// the steps are already ordered by number
+	for _, r := range steps {
+		// check if this is the first step in the stage
+		if _, ok := groups[r.Stage]; !ok {
+			names = append(names, r.Stage)
+		}
+
+		groups[r.Stage] = append(groups[r.Stage], r)
+	}
+
+	// check if none of the steps ran in a stage
+	if len(names) == 0 || (len(names) == 1 && len(names[0]) == 0) {
+		return nil
+	}
+
+	stages := make([]*Stage, 0, len(names))
+
+	for _, name := range names {
+		stages = append(stages, newStage(name, groups[name]))
+	}
+
+	return stages
+}
+
+// stageNote is a helper function to produce the note for a
+// stage with the summed duration of the steps in the stage.
+func stageNote(s *Stage) string {
+	// check if the stage has a name
+	name := s.Name
+	if len(name) == 0 {
+		name = "(no stage)"
+	}
+
+	return fmt.Sprintf("%s (%s step time)", name, s.Time)
+}
+
+// stageRows is a helper function to produce step rows grouped by
+// stage, with a subtotal row for each stage, in the build summary table.
+func stageRows(table *uitable.Table, stages []*Stage, compare bool) {
+	logrus.Debug("adding stage information to build summary table")
+
+	// iterate through all stages in the list
+	for _, s := range stages {
+		// add the step rows for the stage to the table
+		stepRows(table, s.Steps, compare)
+
+		logrus.Tracef("adding stage %s to build summary table", s.Name)
+
+		// add a subtotal row to the table with the specified values
+		//
+		// https://pkg.go.dev/github.com/gosuri/uitable?tab=doc#Table.AddRow
+		table.AddRow(append([]any{"stage", stageNote(s), "", s.Totals.Status, "", s.Totals.Elapsed(), s.Totals.Lines, humanize.Bytes(s.Totals.Size), s.Totals.Throughput(), ""}, compareCells(s.Totals, compare)...)...)
+	}
+}
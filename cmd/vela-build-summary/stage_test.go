@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-vela/server/constants"
+)
+
+// testStatus is a helper function to produce a step
+// that never started running with the provided status.
+func testStatus(status string) *Resource {
+	return &Resource{Type: "step", Stage: "test", Status: status}
+}
+
+func TestStageStatus(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{
+			name: "no steps",
+			want: constants.StatusPending,
+		},
+		{
+			name:     "success",
+			statuses: []string{constants.StatusSuccess, constants.StatusSkipped},
+			want:     constants.StatusSuccess,
+		},
+		{
+			name:     "skipped",
+			statuses: []string{constants.StatusSkipped, constants.StatusSkipped},
+			want:     constants.StatusSkipped,
+		},
+		{
+			name:     "failure over running",
+			statuses: []string{constants.StatusSuccess, constants.StatusRunning, constants.StatusFailure},
+			want:     constants.StatusFailure,
+		},
+		{
+			name:     "failure over error",
+			statuses: []string{constants.StatusError, constants.StatusFailure},
+			want:     constants.StatusFailure,
+		},
+		{
+			name:     "error over killed",
+			statuses: []string{constants.StatusKilled, constants.StatusError},
+			want:     constants.StatusError,
+		},
+		{
+			name:     "killed over canceled",
+			statuses: []string{constants.StatusCanceled, constants.StatusKilled},
+			want:     constants.StatusKilled,
+		},
+		{
+			name:     "canceled over running",
+			statuses: []string{constants.StatusRunning, constants.StatusCanceled},
+			want:     constants.StatusCanceled,
+		},
+		{
+			name:     "running over pending",
+			statuses: []string{constants.StatusPending, constants.StatusRunning, constants.StatusSuccess},
+			want:     constants.StatusRunning,
+		},
+		{
+			name:     "pending over success",
+			statuses: []string{constants.StatusSuccess, constants.StatusPending},
+			want:     constants.StatusPending,
+		},
+		{
+			name:     "unknown status",
+			statuses: []string{"foo"},
+			want:     constants.StatusPending,
+		},
+		{
+			name:     "unknown status with success",
+			statuses: []string{"foo", constants.StatusSuccess},
+			want:     constants.StatusSuccess,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var steps []*Resource
+
+			for _, status := range test.statuses {
+				steps = append(steps, testStatus(status))
+			}
+
+			got := stageStatus(steps)
+
+			if got != test.want {
+				t.Errorf("stageStatus is %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewStage(t *testing.T) {
+	// setup types
+	running := testRun(2, "test", 110, 0)
+	running.Status = constants.StatusRunning
+	running.Duration = 5 * time.Second
+
+	pending := testRun(2, "test", 0, 0)
+	pending.Status = constants.StatusPending
+
+	// setup tests
+	tests := []struct {
+		name     string
+		steps    []*Resource
+		status   string
+		started  int64
+		finished int64
+		duration time.Duration
+		time     time.Duration
+		lines    int
+		excluded int
+	}{
+		{
+			name:     "parallel steps",
+			steps:    []*Resource{testRun(1, "test", 100, 110), testRun(2, "test", 102, 120)},
+			status:   constants.StatusSuccess,
+			started:  100,
+			finished: 120,
+			duration: 20 * time.Second,
+			time:     28 * time.Second,
+			lines:    2,
+		},
+		{
+			name:     "steps with a gap",
+			steps:    []*Resource{testRun(1, "test", 100, 110), testRun(2, "test", 115, 120)},
+			status:   constants.StatusSuccess,
+			started:  100,
+			finished: 120,
+			duration: 20 * time.Second,
+			time:     15 * time.Second,
+			lines:    2,
+		},
+		{
+			name:     "running step",
+			steps:    []*Resource{testRun(1, "test", 100, 110), running},
+			status:   constants.StatusRunning,
+			started:  100,
+			duration: 15 * time.Second,
+			time:     15 * time.Second,
+			lines:    1,
+			excluded: 1,
+		},
+		{
+			name:     "step that never ran",
+			steps:    []*Resource{testRun(1, "test", 100, 110), pending},
+			status:   constants.StatusPending,
+			started:  100,
+			finished: 110,
+			duration: 10 * time.Second,
+			time:     10 * time.Second,
+			lines:    1,
+		},
+		{
+			name:   "no steps ran",
+			steps:  []*Resource{pending},
+			status: constants.StatusPending,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range test.steps {
+				r.Lines = 1
+			}
+
+			got := newStage("test", test.steps)
+
+			if got.Totals.Status != test.status {
+				t.Errorf("Status is %s, want %s", got.Totals.Status, test.status)
+			}
+
+			if got.Totals.Started != test.started || got.Totals.Finished != test.finished {
+				t.Errorf("stage ran from %d to %d, want %d to %d", got.Totals.Started, got.Totals.Finished, test.started, test.finished)
+			}
+
+			// the duration is the wall-clock span rather than the summed step time
+			if got.Totals.Duration != test.duration {
+				t.Errorf("Duration is %s, want %s", got.Totals.Duration, test.duration)
+			}
+
+			if got.Time != test.time {
+				t.Errorf("Time is %s, want %s", got.Time, test.time)
+			}
+
+			if got.Totals.Lines != test.lines {
+				t.Errorf("Lines is %d, want %d", got.Totals.Lines, test.lines)
+			}
+
+			if got.Excluded != test.excluded {
+				t.Errorf("Excluded is %d, want %d", got.Excluded, test.excluded)
+			}
+		})
+	}
+}
+
+func TestNewStages(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name  string
+		steps []*Resource
+		want  []string
+		sizes []int
+	}{
+		{
+			name: "no steps",
+		},
+		{
+			name:  "stages ordered by first step",
+			steps: []*Resource{testRun(1, "init", 100, 101), testRun(2, "test", 101, 110), testRun(3, "publish", 101, 105), testRun(4, "test", 110, 120)},
+			want:  []string{"init", "test", "publish"},
+			sizes: []int{1, 2, 1},
+		},
+		{
+			name:  "stageless steps",
+			steps: []*Resource{testRun(1, "", 100, 101), testRun(2, "", 101, 110)},
+		},
+		{
+			name:  "stageless and staged steps",
+			steps: []*Resource{testRun(1, "", 100, 101), testRun(2, "test", 101, 110)},
+			want:  []string{"", "test"},
+			sizes: []int{1, 1},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := newStages(test.steps)
+
+			if test.want == nil {
+				if got != nil {
+					t.Errorf("newStages is %v, want nil", got)
+				}
+
+				return
+			}
+
+			var (
+				names []string
+				sizes []int
+			)
+
+			for _, s := range got {
+				names = append(names, s.Name)
+				sizes = append(sizes, len(s.Steps))
+			}
+
+			if !slices.Equal(names, test.want) {
+				t.Errorf("stages are %v, want %v", names, test.want)
+			}
+
+			if !slices.Equal(sizes, test.sizes) {
+				t.Errorf("steps in stages are %v, want %v", sizes, test.sizes)
+			}
+		})
+	}
+}
+
+func TestStageNote(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		stage *Stage
+		want  string
+	}{
+		{
+			stage: newStage("test", []*Resource{testRun(1, "test", 100, 110), testRun(2, "test", 102, 120)}),
+			want:  "test (28s step time)",
+		},
+		{
+			stage: newStage("", []*Resource{testRun(1, "", 100, 110)}),
+			want:  "(no stage) (10s step time)",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			got := stageNote(test.stage)
+
+			if got != test.want {
+				t.Errorf("stageNote is %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,6 @@
 package main
 
 import (
-	"bytes"
-	"fmt"
 	"sort"
 	"time"
 
@@ -16,29 +14,31 @@ import (
 	api "github.com/go-vela/server/api/types"
 )
 
-// stepLines is a helper function to calculate the total lines of logs
-// a step produced by measuring the newlines (\n) in that log entry.
-func stepLines(s *api.Step, logs *[]api.Log) int {
-	logrus.Debugf("calculating lines of logs for step %s for build summary table", s.GetName())
+// stepExcerpt is a helper function to capture the last
+// lines of logs a step produced from the log index.
+func stepExcerpt(s *api.Step, logs *logIndex) string {
+	logrus.Debugf("capturing excerpt of logs for step %s for build summary", s.GetName())
 
-	// create a variable to track the lines of logs for the step
-	var lines int
+	// capture the excerpt for the logs
+	return logs.Step(s.GetID()).Excerpt()
+}
 
-	// iterate through all logs in the list
-	for _, log := range *logs {
-		// check if the log step ID matches the step ID
-		if log.GetStepID() != s.GetID() {
-			continue
-		}
+// stepErrors is a helper function to capture the total lines of logs
+// a step produced reporting an error from the log index.
+func stepErrors(s *api.Step, logs *logIndex) int {
+	logrus.Debugf("capturing errors in logs for step %s for build summary", s.GetName())
 
-		// capture the total lines for the logs
-		lines = bytes.Count(log.GetData(), []byte("\n"))
+	// capture the total errors for the logs
+	return logs.Step(s.GetID()).Errors
+}
 
-		// break out of the for loop
-		break
-	}
+// stepLines is a helper function to capture the total lines of logs
+// a step produced by measuring the newlines (\n) in the log index.
+func stepLines(s *api.Step, logs *logIndex) int {
+	logrus.Debugf("calculating lines of logs for step %s for build summary table", s.GetName())
 
-	return lines
+	// capture the total lines for the logs
+	return logs.Step(s.GetID()).Lines
 }
 
 // stepRate is a helper function to calculate the total size of logs
@@ -50,6 +50,14 @@ func stepRate(duration string, size uint64) int64 {
 	// calculate the timestamp duration in seconds
 	s := (float64(d) / float64(time.Second))
 
+	// check if the step ran for any duration
+	//
+	// this avoids dividing by zero for a step that hasn't
+	// started running or finished in less than a second
+	if s <= 0 {
+		return 0
+	}
+
 	// return the rate of bytes per second
 	return int64(float64(size) / s)
 }
@@ -68,63 +76,27 @@ func stepReverse(s []api.Step) []api.Step {
 }
 
 // stepRows is a helper function to produce step rows in the build summary table.
-func stepRows(table *uitable.Table, logs *[]api.Log, steps *[]api.Step, buildLines *int, buildSize *uint64) {
+//
+// If compare is enabled, the values from and change from the previous build are added to each row.
+func stepRows(table *uitable.Table, steps []*Resource, compare bool) {
 	logrus.Debug("adding step information to build summary table")
 
 	// iterate through all steps in the list
-	for _, s := range stepReverse(*steps) {
-		logrus.Tracef("adding step %s to build summary table", s.GetName())
-
-		// calculate lines based off the step logs
-		//
-
-		lines := stepLines(&s, logs)
-
-		// calculate size based off the step logs
-		//
-
-		size := stepSize(&s, logs)
-
-		// calculate duration based off the step timestamps
-		duration := s.Duration()
-
-		// calculate rate based off step duration and size
-		rate := fmt.Sprintf("%d B/s", stepRate(duration, size))
-
-		// update the lines of build logs with the lines of step logs
-		*buildLines = *buildLines + lines
-
-		// update the size of the build logs with the size of the step logs
-		*buildSize = *buildSize + size
+	for _, r := range steps {
+		logrus.Tracef("adding step %s to build summary table", r.Name)
 
 		// add a row to the table with the specified values
 		//
 		// https://pkg.go.dev/github.com/gosuri/uitable?tab=doc#Table.AddRow
-		table.AddRow("step", s.GetName(), s.GetNumber(), s.GetStatus(), duration, lines, humanize.Bytes(size), rate)
+		table.AddRow(append([]any{"step", r.Name, r.Number, r.Status, r.Wait(), r.Elapsed(), r.Lines, humanize.Bytes(r.Size), r.Throughput(), r.Leeway()}, compareCells(r, compare)...)...)
 	}
 }
 
-// stepSize is a helper function to calculate the total size of logs
-// a step produced by measuring the data in that log entry.
-func stepSize(s *api.Step, logs *[]api.Log) uint64 {
+// stepSize is a helper function to capture the total size of logs
+// a step produced by measuring the data in the log index.
+func stepSize(s *api.Step, logs *logIndex) uint64 {
 	logrus.Debugf("calculating size of logs for step %s for build summary table", s.GetName())
 
-	// create a variable to track the size of logs for the step
-	var size uint64
-
-	// iterate through all logs in the list
-	for _, log := range *logs {
-		// check if the log step ID matches the step ID
-		if log.GetStepID() != s.GetID() {
-			continue
-		}
-
-		// capture the total size for the logs
-		size = uint64(len(log.GetData()))
-
-		// break out of the for loop
-		break
-	}
-
-	return size
+	// capture the total size for the logs
+	return logs.Step(s.GetID()).Size
 }
@@ -0,0 +1,328 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/go-vela/server/api/types"
+)
+
+// Summary represents the metrics captured for a build summary.
+type Summary struct {
+	// build captured from the Vela server
+	Build *api.Build
+	// metrics captured for the build as a whole
+	Totals *Resource
+	// metrics captured for each service in the build
+	Services []*Resource
+	// metrics captured for each step in the build
+	Steps []*Resource
+	// metrics captured for each stage of steps in the build
+	Stages []*Stage
+	// steps on the critical path of the build in the order they ran
+	CriticalPath []*Resource
+	// breakdown of where the time for the build was spent
+	Breakdown *Breakdown
+	// parallelism and utilization of the build
+	Utilization *Utilization
+	// build summary for the previous build the build was compared against
+	Previous *Summary
+	// services and steps in the previous build that didn't run in the build
+	Removed []*Resource
+	// number of services and steps excluded from the totals
+	Excluded int
+	// step running the plugin, excluded from comparisons since it's still running
+	Plugin *api.Step
+}
+
+// Resource represents the metrics captured for a single resource in a build.
+type Resource struct {
+	// type of the resource (build, service or step)
+	Type string
+	// name of the resource
+	Name string
+	// number of the resource
+	Number int
+	// status of the resource
+	Status string
+	// stage the resource ran in
+	Stage string
+	// image the resource ran with
+	Image string
+	// host the resource ran on
+	Host string
+	// exit code the resource finished with
+	ExitCode int
+	// error the resource encountered
+	Error string
+	// unix timestamp for when the resource was created
+	Created int64
+	// unix timestamp for when the resource was enqueued
+	Enqueued int64
+	// unix timestamp for when the resource started
+	Started int64
+	// unix timestamp for when the resource finished
+	Finished int64
+	// duration the resource ran for
+	Duration time.Duration
+	// lines of logs the resource produced
+	Lines int
+	// size of logs the resource produced in bytes
+	Size uint64
+	// lines of logs the resource produced reporting an error
+	Errors int
+	// rate of logs the resource produced in bytes per second
+	Rate int64
+	// last lines of logs the resource produced
+	Excerpt string
+	// whether the step is on the critical path of the build
+	Critical bool
+	// duration the step could have been delayed without delaying the build
+	Slack time.Duration
+	// metrics captured for the resource in the previous build
+	Previous *Resource
+	// whether the resource didn't run in the previous build
+	New bool
+	// metrics that regressed from the previous build
+	Regressions []string
+}
+
+// Resources returns the services and steps captured for the build summary.
+func (s *Summary) Resources() []*Resource {
+	return append(append([]*Resource{}, s.Services...), s.Steps...)
+}
+
+// Queued returns the duration the resource waited before it started.
+//
+// The duration is measured from when the resource was enqueued,
+// or created if it was never enqueued, until it started running.
+func (r *Resource) Queued() time.Duration {
+	// use the enqueued timestamp if it exists
+	queued := r.Enqueued
+	if queued == 0 {
+		queued = r.Created
+	}
+
+	// check if the resource never queued or started
+	if queued == 0 || r.Started < queued {
+		return 0
+	}
+
+	return time.Duration(r.Started-queued) * time.Second
+}
+
+// Leeway returns the slack the resource had formatted for display.
+//
+// A "critical" is returned for steps on the critical path of the build,
+// and a "-" is returned for resources that aren't steps or never started
+// running, since the critical path is only analyzed for those steps.
+func (r *Resource) Leeway() string {
+	switch {
+	case r.Critical:
+		return "critical"
+	case r.Type != "step" || r.Started == 0:
+		return "-"
+	default:
+		return r.Slack.String()
+	}
+}
+
+// Running returns true if the resource started but hasn't finished running.
+//
+// Resources with a final status aren't running, even if they were
+// killed or canceled without a timestamp for when they finished.
+func (r *Resource) Running() bool {
+	return r.Started > 0 && r.Finished == 0 && !terminal(r.Status)
+}
+
+// Complete returns true if the resource started and finished running.
+//
+// Resources that are pending, still running, skipped or canceled
+// before they started aren't complete, so they're excluded from the
+// log lines, log size and log errors in the totals for the build.
+// Resources that were killed or canceled after they started are
+// complete, since they have a final status and both timestamps.
+func (r *Resource) Complete() bool {
+	return terminal(r.Status) && r.Started > 0 && r.Finished > 0
+}
+
+// Elapsed returns the duration the resource ran for formatted for display.
+//
+// A "-" is returned for resources that never started running or have
+// no timestamp for when they finished, and the duration so far is
+// returned for resources that are still running.
+func (r *Resource) Elapsed() string {
+	switch {
+	case r.Started == 0:
+		return "-"
+	case r.Running():
+		return fmt.Sprintf("%s (running)", r.Duration)
+	case r.Finished == 0:
+		return "-"
+	default:
+		return r.Duration.String()
+	}
+}
+
+// Throughput returns the rate of logs the resource produced formatted for display.
+//
+// A "-" is returned for resources that aren't complete or finished in less
+// than a second, since a rate can't be calculated for those resources.
+func (r *Resource) Throughput() string {
+	// check if a rate can be calculated for the resource
+	if !r.Complete() || r.Duration < time.Second {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d B/s", r.Rate)
+}
+
+// total is a helper function to update the totals for the
+// build with the metrics for the provided resource.
+//
+// Only complete resources are included in the totals, so the totals
+// don't change between runs for resources that are still running.
+func (s *Summary) total(r *Resource) {
+	// check if the resource is complete
+	if !r.Complete() {
+		logrus.Tracef("excluding %s %s from build summary totals", r.Type, r.Name)
+
+		s.Excluded++
+
+		return
+	}
+
+	s.Totals.Lines += r.Lines
+	s.Totals.Size += r.Size
+	s.Totals.Errors += r.Errors
+}
+
+// newSummary is a helper function to capture the metrics for
+// the build, services and steps in a single build summary.
+func newSummary(build *api.Build, index *logIndex, needs map[string][]string, services *[]api.Service, steps *[]api.Step) *Summary {
+	logrus.Debug("capturing metrics for build summary")
+
+	// create the summary with the build and its totals
+	summary := &Summary{
+		Build: build,
+		Totals: &Resource{
+			Type:     "build",
+			Number:   build.GetNumber(),
+			Status:   build.GetStatus(),
+			Host:     build.GetHost(),
+			Error:    build.GetError(),
+			Created:  build.GetCreated(),
+			Enqueued: build.GetEnqueued(),
+			Started:  build.GetStarted(),
+			Finished: build.GetFinished(),
+		},
+	}
+
+	// iterate through all services in the list
+	for _, s := range serviceReverse(*services) {
+		// calculate duration based off the service timestamps
+		duration := s.Duration()
+
+		// calculate size based off the service logs
+		size := serviceSize(&s, index)
+
+		// parse the string duration into a timestamp duration
+		//
+		// the duration is zero for a service that hasn't started running
+		d, _ := time.ParseDuration(duration)
+
+		r := &Resource{
+			Type:     "service",
+			Name:     s.GetName(),
+			Number:   s.GetNumber(),
+			Status:   s.GetStatus(),
+			Image:    s.GetImage(),
+			Host:     s.GetHost(),
+			ExitCode: s.GetExitCode(),
+			Error:    s.GetError(),
+			Created:  s.GetCreated(),
+			Started:  s.GetStarted(),
+			Finished: s.GetFinished(),
+			Duration: max(d, 0),
+			Lines:    serviceLines(&s, index),
+			Size:     size,
+			Errors:   serviceErrors(&s, index),
+			Rate:     serviceRate(duration, size),
+			Excerpt:  serviceExcerpt(&s, index),
+		}
+
+		// update the totals for the build with the service metrics
+		summary.total(r)
+
+		summary.Services = append(summary.Services, r)
+	}
+
+	// iterate through all steps in the list
+	for _, s := range stepReverse(*steps) {
+		// calculate duration based off the step timestamps
+		duration := s.Duration()
+
+		// calculate size based off the step logs
+		size := stepSize(&s, index)
+
+		// parse the string duration into a timestamp duration
+		//
+		// the duration is zero for a step that hasn't started running
+		d, _ := time.ParseDuration(duration)
+
+		r := &Resource{
+			Type:     "step",
+			Name:     s.GetName(),
+			Number:   s.GetNumber(),
+			Status:   s.GetStatus(),
+			Stage:    s.GetStage(),
+			Image:    s.GetImage(),
+			Host:     s.GetHost(),
+			ExitCode: s.GetExitCode(),
+			Error:    s.GetError(),
+			Created:  s.GetCreated(),
+			Started:  s.GetStarted(),
+			Finished: s.GetFinished(),
+			Duration: max(d, 0),
+			Lines:    stepLines(&s, index),
+			Size:     size,
+			Errors:   stepErrors(&s, index),
+			Rate:     stepRate(duration, size),
+			Excerpt:  stepExcerpt(&s, index),
+		}
+
+		// update the totals for the build with the step metrics
+		summary.total(r)
+
+		summary.Steps = append(summary.Steps, r)
+	}
+
+	// group the steps by stage with the metrics for each stage
+	summary.Stages = newStages(summary.Steps)
+
+	// analyze the critical path of the build from the stage needs
+	summary.critical(needs)
+
+	// calculate duration based off the build timestamps
+	duration := build.Duration()
+
+	// parse the string duration into a timestamp duration
+	d, _ := time.ParseDuration(duration)
+
+	summary.Totals.Duration = max(d, 0)
+
+	// calculate rate based off build duration and size
+	summary.Totals.Rate = buildRate(duration, summary.Totals.Size)
+
+	// calculate where the time for the build was spent
+	summary.Breakdown = newBreakdown(summary)
+
+	// calculate the parallelism and utilization of the build
+	summary.Utilization = newUtilization(summary)
+
+	return summary
+}
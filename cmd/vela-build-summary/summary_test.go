@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-vela/server/constants"
+)
+
+func TestResource_Complete(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		resource *Resource
+		running  bool
+		want     bool
+	}{
+		{
+			name:     "success",
+			resource: &Resource{Status: constants.StatusSuccess, Started: 1, Finished: 2},
+			want:     true,
+		},
+		{
+			name:     "failure",
+			resource: &Resource{Status: constants.StatusFailure, Started: 1, Finished: 2},
+			want:     true,
+		},
+		{
+			name:     "canceled after it started",
+			resource: &Resource{Status: constants.StatusCanceled, Started: 1, Finished: 2},
+			want:     true,
+		},
+		{
+			name:     "killed after it started",
+			resource: &Resource{Status: constants.StatusKilled, Started: 1, Finished: 2},
+			want:     true,
+		},
+		{
+			name:     "killed without a finished timestamp",
+			resource: &Resource{Status: constants.StatusKilled, Started: 1},
+		},
+		{
+			name:     "canceled before it started",
+			resource: &Resource{Status: constants.StatusCanceled, Finished: 2},
+		},
+		{
+			name:     "skipped",
+			resource: &Resource{Status: constants.StatusSkipped},
+		},
+		{
+			name:     "pending",
+			resource: &Resource{Status: constants.StatusPending},
+		},
+		{
+			name:     "running",
+			resource: &Resource{Status: constants.StatusRunning, Started: 1},
+			running:  true,
+		},
+		{
+			name:     "running with a finished timestamp",
+			resource: &Resource{Status: constants.StatusRunning, Started: 1, Finished: 2},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.resource.Complete(); got != test.want {
+				t.Errorf("Complete is %v, want %v", got, test.want)
+			}
+
+			if got := test.resource.Running(); got != test.running {
+				t.Errorf("Running is %v, want %v", got, test.running)
+			}
+		})
+	}
+}
+
+func TestResource_Elapsed(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		resource *Resource
+		want     string
+	}{
+		{
+			name:     "complete",
+			resource: &Resource{Status: constants.StatusSuccess, Started: 1, Finished: 91, Duration: 90 * time.Second},
+			want:     "1m30s",
+		},
+		{
+			name:     "running",
+			resource: &Resource{Status: constants.StatusRunning, Started: 1, Duration: 5 * time.Second},
+			want:     "5s (running)",
+		},
+		{
+			name:     "never started",
+			resource: &Resource{Status: constants.StatusSkipped},
+			want:     "-",
+		},
+		{
+			name:     "killed without a finished timestamp",
+			resource: &Resource{Status: constants.StatusKilled, Started: 1, Duration: time.Hour},
+			want:     "-",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.resource.Elapsed()
+
+			if got != test.want {
+				t.Errorf("Elapsed is %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResource_Throughput(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		resource *Resource
+		want     string
+	}{
+		{
+			name:     "complete",
+			resource: &Resource{Status: constants.StatusSuccess, Started: 1, Finished: 11, Duration: 10 * time.Second, Rate: 512},
+			want:     "512 B/s",
+		},
+		{
+			name:     "finished in less than a second",
+			resource: &Resource{Status: constants.StatusSuccess, Started: 1, Finished: 1, Duration: 0, Rate: 0},
+			want:     "-",
+		},
+		{
+			name:     "running",
+			resource: &Resource{Status: constants.StatusRunning, Started: 1, Duration: 10 * time.Second, Rate: 512},
+			want:     "-",
+		},
+		{
+			name:     "skipped",
+			resource: &Resource{Status: constants.StatusSkipped},
+			want:     "-",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.resource.Throughput()
+
+			if got != test.want {
+				t.Errorf("Throughput is %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSummary_Total(t *testing.T) {
+	// setup types
+	s := testSummary(1)
+
+	resources := []*Resource{
+		{Status: constants.StatusSuccess, Started: 1, Finished: 2, Lines: 10, Size: 100, Errors: 1},
+		{Status: constants.StatusCanceled, Started: 1, Finished: 3, Lines: 5, Size: 50},
+		{Status: constants.StatusRunning, Started: 1, Lines: 20, Size: 200, Errors: 2},
+		{Status: constants.StatusSkipped},
+		{Status: constants.StatusPending},
+	}
+
+	for _, r := range resources {
+		s.total(r)
+	}
+
+	if s.Totals.Lines != 15 || s.Totals.Size != 150 || s.Totals.Errors != 1 {
+		t.Errorf("totals are %d lines, %d bytes and %d errors, want 15, 150 and 1", s.Totals.Lines, s.Totals.Size, s.Totals.Errors)
+	}
+
+	if s.Excluded != 3 {
+		t.Errorf("Excluded is %d, want 3", s.Excluded)
+	}
+}
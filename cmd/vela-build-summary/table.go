@@ -4,12 +4,11 @@ package main
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"strings"
 
 	"github.com/gosuri/uitable"
 	"github.com/sirupsen/logrus"
-
-	api "github.com/go-vela/server/api/types"
 )
 
 // table is a helper function to output the provided build summary in a table.
@@ -18,7 +17,10 @@ import (
 // build, such as name, number, status and duration of runtime. Also in the
 // table are some more fine grained metrics on log size and rate of logs
 // produced throughout the lifecycle of each resource.
-func table(build *api.Build, logs *[]api.Log, services *[]api.Service, steps *[]api.Step) error {
+//
+// For pipelines using stages, the steps are grouped by
+// stage with a subtotal row for each stage.
+func table(w io.Writer, s *Summary) error {
 	logrus.Debug("creating table for build summary")
 
 	// create a new table
@@ -40,29 +42,71 @@ func table(build *api.Build, logs *[]api.Log, services *[]api.Service, steps *[]
 	// set of build fields we display in a table
 	//
 	// https://pkg.go.dev/github.com/gosuri/uitable?tab=doc#Table.AddRow
-	table.AddRow("TYPE", "NAME", "NUMBER", "STATUS", "DURATION", "LOG LINES", "LOG SIZE", "LOG RATE")
+	headers := []any{"TYPE", "NAME", "NUMBER", "STATUS", "QUEUED", "DURATION", "LOG LINES", "LOG SIZE", "LOG RATE", "SLACK"}
+	separators := []any{"----------", "--------------------", "----------", "----------", "----------", "----------", "----------", "---------------", "---------------", "----------"}
+
+	// check if the build was compared against a previous build
+	compare := s.Previous != nil
+	if compare {
+		// add the columns for the values from and change from the previous build
+		headers = append(headers, "BASE STATUS", "BASE DURATION", "BASE LOG LINES", "BASE LOG SIZE", "Δ DURATION", "Δ LOG LINES", "Δ LOG SIZE")
+		separators = append(separators, "----------", "----------", "----------", "---------------", "----------", "----------", "----------")
+	}
 
-	// create a variable to track the lines of logs for the build
-	buildLines := new(int)
-	// create a variable to track the size of logs for the build
-	buildSize := new(uint64)
+	table.AddRow(headers...)
 
 	// add the service rows to the table
-	serviceRows(table, logs, services, buildLines, buildSize)
+	serviceRows(table, s.Services, compare)
 
-	// add the step rows to the table
-	stepRows(table, logs, steps, buildLines, buildSize)
+	// check if the steps ran in stages
+	if len(s.Stages) > 0 {
+		// add the step rows grouped by stage to the table
+		stageRows(table, s.Stages, compare)
+	} else {
+		// add the step rows to the table
+		stepRows(table, s.Steps, compare)
+	}
 
 	// add a separation row to the table with the specified values
 	//
 	// https://pkg.go.dev/github.com/gosuri/uitable?tab=doc#Table.AddRow
-	table.AddRow("----------", "--------------------", "----------", "----------", "----------", "----------", "---------------", "---------------")
+	table.AddRow(separators...)
 
 	// add the build row to the table
-	buildRow(table, build, buildLines, buildSize)
-
-	// ensure we output table to stdout
-	fmt.Fprintln(os.Stdout, table)
-
-	return nil
+	buildRow(table, s.Totals, s.Excluded, compare)
+
+	// output the table to the provided writer
+	_, err := fmt.Fprintln(w, table)
+	if err != nil {
+		return err
+	}
+
+	// output the breakdown of where the time for the build was spent
+	_, err = fmt.Fprintf(w, "\nBREAKDOWN (%s total): %s\n", s.Breakdown.Total(), s.Breakdown)
+	if err != nil {
+		return err
+	}
+
+	// output the parallelism and utilization of the build
+	_, err = fmt.Fprintf(w, "\nUTILIZATION: %s\n", s.Utilization)
+	if err != nil {
+		return err
+	}
+
+	// check if the build has a critical path
+	if len(s.CriticalPath) > 0 {
+		// output the critical path to the provided writer
+		_, err = fmt.Fprintf(w, "\nCRITICAL PATH (%s): %s\n", criticalSpan(s), strings.Join(criticalNames(s), " -> "))
+		if err != nil {
+			return err
+		}
+	}
+
+	// check if the build was compared against a previous build
+	if compare {
+		// output the build the summary was compared against
+		_, err = fmt.Fprintf(w, "\nCOMPARED TO BUILD %s: %s\n", compareBuild(s), compareNote(s))
+	}
+
+	return err
 }
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+)
+
+// templateFuncs represents the helper functions
+// available to templates for the build summary.
+var templateFuncs = template.FuncMap{
+	// humanize a size in bytes (i.e. 8.2 kB)
+	"bytes": humanize.Bytes,
+	// humanize a duration rounded to the second (i.e. 1m30s)
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Second).String()
+	},
+	// format a unix timestamp as RFC 3339 (i.e. 2021-01-01T00:00:00Z)
+	"timestamp": func(t int64) string {
+		// check if the timestamp is set
+		if t == 0 {
+			return ""
+		}
+
+		return time.Unix(t, 0).UTC().Format(time.RFC3339)
+	},
+	// humanize a unix timestamp relative to now (i.e. 3 minutes ago)
+	"ago": func(t int64) string {
+		// check if the timestamp is set
+		if t == 0 {
+			return ""
+		}
+
+		return humanize.Time(time.Unix(t, 0))
+	},
+	// add an emoji to a status (i.e. ✅ success)
+	"emoji": markdownStatus,
+	// convert a string to lower case
+	"lower": strings.ToLower,
+	// convert a string to upper case
+	"upper": strings.ToUpper,
+}
+
+// templateOutput is a helper function to output the provided
+// build summary with a user provided Go text/template.
+//
+// The template is executed against the build summary, so it has
+// access to the build, the services and steps in the build and
+// the metrics captured for each of them.
+//
+// https://pkg.go.dev/text/template
+func templateOutput(w io.Writer, s *Summary, text string) error {
+	logrus.Debug("creating templated output for build summary")
+
+	// parse the template for the output
+	//
+	// https://pkg.go.dev/text/template#Template.Parse
+	tmpl, err := template.New("summary").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	// execute the template against the build summary
+	//
+	// https://pkg.go.dev/text/template#Template.Execute
+	return tmpl.Execute(w, s)
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTemplateOutput(t *testing.T) {
+	// setup types
+	ago := time.Now().Add(-3 * time.Minute).Unix()
+
+	// setup tests
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		failure bool
+	}{
+		{
+			name: "build",
+			text: "{{ .Build.GetRepo.GetOrg }}/{{ .Build.GetRepo.GetName }} #{{ .Totals.Number }} {{ .Totals.Status }}",
+			want: "foo/bar #1 failure",
+		},
+		{
+			name: "steps",
+			text: "{{ range .Steps }}{{ .Name }}={{ .Elapsed }} {{ end }}",
+			want: "clone=10s test=40s publish=- ",
+		},
+		{
+			name: "bytes",
+			text: "{{ bytes .Totals.Size }} {{ bytes 8200 }}",
+			want: "93 B 8.2 kB",
+		},
+		{
+			name: "duration",
+			text: "{{ duration .Totals.Duration }} {{ (index .Steps 1).Duration | duration }}",
+			want: "1m0s 40s",
+		},
+		{
+			name: "timestamp",
+			text: "{{ timestamp .Totals.Started }}|{{ timestamp 0 }}",
+			want: "1970-01-01T00:16:50Z|",
+		},
+		{
+			name: "ago",
+			text: fmt.Sprintf("{{ ago %d }}|{{ ago 0 }}", ago),
+			want: "3 minutes ago|",
+		},
+		{
+			name: "emoji",
+			text: "{{ emoji .Totals.Status }}",
+			want: "❌ failure",
+		},
+		{
+			name: "lower and upper",
+			text: "{{ upper .Totals.Status }} {{ lower \"FOO\" }}",
+			want: "FAILURE foo",
+		},
+		{
+			name:    "invalid template",
+			text:    "{{ .Totals.Status",
+			failure: true,
+		},
+		{
+			name:    "unknown field",
+			text:    "{{ .Missing }}",
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+
+			err := templateOutput(buf, testBuildSummary(), test.text)
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("templateOutput should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("templateOutput returned err: %v", err)
+			}
+
+			if buf.String() != test.want {
+				t.Errorf("templateOutput is %q, want %q", buf.String(), test.want)
+			}
+		})
+	}
+}
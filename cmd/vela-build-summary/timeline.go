@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/server/constants"
+)
+
+const (
+	// timelineMinWidth represents the minimum number of
+	// columns used to draw the bars in the timeline.
+	timelineMinWidth = 10
+	// timelineMaxLabel represents the maximum number of
+	// columns used to display the names in the timeline.
+	timelineMaxLabel = 30
+	// timelineDurationWidth represents the number of columns
+	// used to display the durations in the timeline.
+	timelineDurationWidth = 10
+)
+
+// timelineGroup represents a group of resources
+// displayed together in the timeline.
+type timelineGroup struct {
+	// name of the group
+	Name string
+	// resources in the group
+	Resources []*Resource
+}
+
+// timelineGroups is a helper function to group the services
+// and steps in the build summary for the timeline.
+//
+// Services are grouped together, and steps are grouped
+// by the stage they ran in based off the order the
+// stages first appear in the build.
+func timelineGroups(s *Summary) []*timelineGroup {
+	groups := []*timelineGroup{}
+
+	// add a group for the services
+	if len(s.Services) > 0 {
+		groups = append(groups, &timelineGroup{Name: "services", Resources: s.Services})
+	}
+
+	// create a map to track the group for each stage
+	stages := make(map[string]*timelineGroup)
+
+	// add the steps to the group for their stage
+	for _, r := range s.Steps {
+		group, ok := stages[r.Stage]
+		if !ok {
+			// use a generic name for steps without a stage
+			name := "steps"
+			if len(r.Stage) > 0 {
+				name = fmt.Sprintf("stage: %s", r.Stage)
+			}
+
+			group = &timelineGroup{Name: name}
+			stages[r.Stage] = group
+
+			groups = append(groups, group)
+		}
+
+		group.Resources = append(group.Resources, r)
+	}
+
+	return groups
+}
+
+// timelineFill is a helper function to produce the character
+// used to draw the bar for a resource based off its status.
+func timelineFill(status string) string {
+	switch status {
+	case constants.StatusSuccess:
+		return "#"
+	case constants.StatusFailure, constants.StatusError, constants.StatusKilled:
+		return "X"
+	case constants.StatusRunning:
+		return ">"
+	default:
+		return "="
+	}
+}
+
+// timeline is a helper function to output the provided build summary
+// as a horizontal bar for each resource on a timeline.
+//
+// The bars are scaled to the wall-clock span of the build and fit in
+// the provided number of columns, so the parallelism and idle gaps in
+// the build are visible. For very long builds, each column represents
+// a larger slice of time and every resource that ran is drawn with at
+// least one column.
+func timeline(w io.Writer, s *Summary, width int) error {
+	logrus.Debug("creating timeline for build summary")
+
+	groups := timelineGroups(s)
+
+	// capture the span of the timeline based off the build timestamps
+	start, end := s.Totals.Started, s.Totals.Finished
+
+	// create a variable to track the columns used to display the names
+	label := 0
+
+	// extend the span of the timeline to include all resources
+	for _, r := range s.Resources() {
+		if r.Started > 0 && (start == 0 || r.Started < start) {
+			start = r.Started
+		}
+
+		if r.Finished > end {
+			end = r.Finished
+		}
+
+		label = max(label, utf8.RuneCountInString(r.Name)+2)
+	}
+
+	// check if any resources ran in the build
+	if start == 0 {
+		logrus.Debug("no resources ran in build, skipping timeline")
+
+		return nil
+	}
+
+	// check if the timeline has no span
+	if end <= start {
+		end = start + 1
+
+		// use the current time for builds that haven't finished
+		if s.Totals.Finished == 0 {
+			end = max(end, time.Now().Unix())
+		}
+	}
+
+	label = min(label, timelineMaxLabel)
+
+	// calculate the columns used to draw the bars in the timeline
+	bars := max(width-label-timelineDurationWidth-4, timelineMinWidth)
+
+	// calculate the span of the timeline in seconds
+	span := end - start
+
+	// create a buffer to render the timeline in
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "\nTIMELINE (%s, each column is ~%s, # success, X failure, > running, = other)\n",
+		time.Duration(span)*time.Second,
+		(time.Duration(span) * time.Second / time.Duration(bars)).Round(time.Millisecond),
+	)
+
+	for _, group := range groups {
+		fmt.Fprintln(buf, group.Name)
+
+		for _, r := range group.Resources {
+			// truncate the name on runes to fit in the columns for the names
+			name := r.Name
+			if runes := []rune(name); len(runes) > label-2 {
+				name = string(runes[:label-3]) + "~"
+			}
+
+			// create the empty bar for the resource
+			bar := strings.Repeat(" ", bars)
+
+			// check if the resource started running
+			if r.Started > 0 {
+				// use the end of the timeline for resources still running
+				finished := r.Finished
+				if finished == 0 {
+					finished = end
+				}
+
+				// calculate the columns the resource ran between
+				from := int((r.Started - start) * int64(bars) / span)
+				to := int(((finished-start)*int64(bars) + span - 1) / span)
+
+				// ensure every resource that ran is drawn with at least one column
+				from = min(from, bars-1)
+				to = max(to, from+1)
+
+				bar = bar[:from] + strings.Repeat(timelineFill(r.Status), to-from) + bar[to:]
+			}
+
+			// capture the duration for the resource
+			duration := r.Status
+			if r.Started > 0 {
+				duration = r.Duration.String()
+			}
+
+			fmt.Fprintf(buf, "  %-*s |%s| %s\n", label-2, name, bar, duration)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
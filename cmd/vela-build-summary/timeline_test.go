@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/go-vela/server/constants"
+)
+
+// testBars is a helper function to capture the bar
+// drawn for each resource in the provided timeline.
+func testBars(timeline string) map[string]string {
+	bars := make(map[string]string)
+
+	for _, line := range strings.Split(timeline, "\n") {
+		// check if the line draws a bar for a resource
+		name, rest, ok := strings.Cut(strings.TrimSpace(line), " |")
+		if !ok {
+			continue
+		}
+
+		bar, _, _ := strings.Cut(rest, "|")
+
+		bars[strings.TrimSpace(name)] = bar
+	}
+
+	return bars
+}
+
+func TestTimeline(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name   string
+		build  *Resource
+		steps  []*Resource
+		width  int
+		header string
+		want   map[string]string
+	}{
+		{
+			name:  "scaled to the width",
+			build: &Resource{Type: "build", Started: 1000, Finished: 1100},
+			steps: []*Resource{testRun(1, "test", 1000, 1050), testRun(2, "test", 1050, 1100)},
+			width: 41,
+			want: map[string]string{
+				"step1": "##########          ",
+				"step2": "          ##########",
+			},
+		},
+		{
+			name:  "width minimum",
+			build: &Resource{Type: "build", Started: 1000, Finished: 1100},
+			steps: []*Resource{testRun(1, "test", 1000, 1050), testRun(2, "test", 1050, 1100)},
+			want: map[string]string{
+				"step1": "#####     ",
+				"step2": "     #####",
+			},
+		},
+		{
+			name:   "zero span",
+			build:  &Resource{Type: "build", Started: 1000, Finished: 1000},
+			steps:  []*Resource{testRun(1, "test", 1000, 1000)},
+			header: "TIMELINE (1s, each column is ~100ms",
+			want: map[string]string{
+				"step1": "#         ",
+			},
+		},
+		{
+			name:  "running step",
+			build: &Resource{Type: "build", Started: 1000},
+			steps: []*Resource{
+				testRun(1, "test", 1000, 1050),
+				{Type: "step", Name: "step2", Number: 2, Stage: "test", Status: constants.StatusRunning, Started: 1050},
+			},
+			want: map[string]string{
+				"step1": "##########",
+				"step2": "         >",
+			},
+		},
+		{
+			name:  "step that never ran",
+			build: &Resource{Type: "build", Started: 1000, Finished: 1100},
+			steps: []*Resource{
+				testRun(1, "test", 1000, 1100),
+				{Type: "step", Name: "step2", Number: 2, Stage: "test", Status: constants.StatusSkipped},
+			},
+			want: map[string]string{
+				"step1": "##########",
+				"step2": "          ",
+			},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := testSummary(1, test.steps...)
+			s.Totals = test.build
+
+			buf := new(bytes.Buffer)
+
+			err := timeline(buf, s, test.width)
+			if err != nil {
+				t.Fatalf("timeline returned err: %v", err)
+			}
+
+			if !strings.Contains(buf.String(), test.header) {
+				t.Errorf("timeline is %s, want %q", buf.String(), test.header)
+			}
+
+			got := testBars(buf.String())
+
+			for name, want := range test.want {
+				if got[name] != want {
+					t.Errorf("timeline bar for %s is %q, want %q", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeline_NoResources(t *testing.T) {
+	// setup types
+	s := testSummary(1, &Resource{Type: "step", Name: "step1", Status: constants.StatusPending})
+
+	buf := new(bytes.Buffer)
+
+	err := timeline(buf, s, 80)
+	if err != nil {
+		t.Fatalf("timeline returned err: %v", err)
+	}
+
+	if buf.Len() > 0 {
+		t.Errorf("timeline is %q, want no timeline", buf.String())
+	}
+}
+
+func TestTimeline_Truncate(t *testing.T) {
+	// setup types
+	step := testRun(1, "test", 1000, 1100)
+	step.Name = strings.Repeat("é", 40)
+
+	s := testSummary(1, step)
+	s.Totals = &Resource{Type: "build", Started: 1000, Finished: 1100}
+
+	buf := new(bytes.Buffer)
+
+	err := timeline(buf, s, 80)
+	if err != nil {
+		t.Fatalf("timeline returned err: %v", err)
+	}
+
+	if !utf8.Valid(buf.Bytes()) {
+		t.Errorf("timeline is not valid UTF-8: %q", buf.String())
+	}
+
+	want := fmt.Sprintf("  %s~ |", strings.Repeat("é", 27))
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("timeline is %s, want %q", buf.String(), want)
+	}
+}
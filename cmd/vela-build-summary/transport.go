@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// retryBackoff represents the duration to wait before
+// the first retry for a request, which doubles with
+// every following attempt.
+const retryBackoff = 500 * time.Millisecond
+
+// contextTransport represents an HTTP transport that sends
+// every request with the provided context.
+//
+// The Vela SDK doesn't accept a context for API calls, so this
+// ensures the API calls are canceled along with the plugin.
+type contextTransport struct {
+	// context to send every request with
+	ctx context.Context
+	// transport to send the requests with
+	next http.RoundTripper
+}
+
+// RoundTrip sends the HTTP request with the context for the transport.
+//
+// https://pkg.go.dev/net/http#RoundTripper
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
+
+// retryTransport represents an HTTP transport that retries requests
+// failing with a transient error using a jittered exponential backoff.
+//
+// Requests are retried for timeouts, connections that were reset or
+// refused, responses that ended early, rate limits and server errors. When the response includes a Retry-After header,
+// that duration is waited before retrying the request instead.
+type retryTransport struct {
+	// total number of attempts to send each request
+	attempts int
+	// maximum duration to wait between attempts
+	maxWait time.Duration
+	// transport to send the requests with
+	next http.RoundTripper
+}
+
+// RoundTrip sends the HTTP request, retrying it for transient errors.
+//
+// https://pkg.go.dev/net/http#RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		// create a variable to track the request for the attempt
+		r := req
+
+		// check if the body for the request needs to be rewound
+		if attempt > 1 && req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(r)
+
+		// check if the request should be retried
+		//
+		// requests with a body that can't be rewound aren't retried
+		if !retryable(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		// check if all attempts for the request have been used
+		if attempt >= t.attempts {
+			// check if the request failed to send
+			if resp == nil {
+				return nil, err
+			}
+
+			// the Vela SDK ignores errors without a JSON body, which is
+			// common for proxies, so an error is returned explicitly
+			//
+			// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#CheckResponse
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			resp.Body.Close()
+
+			return nil, fmt.Errorf("%s %s failed after %d attempts: %s: %s", req.Method, req.URL.Path, attempt, resp.Status, strings.TrimSpace(string(body)))
+		}
+
+		// capture the duration to wait before retrying the request
+		wait := retryWait(resp, attempt, t.maxWait)
+
+		// capture the reason the request is being retried
+		reason := fmt.Sprint(err)
+		if resp != nil {
+			reason = resp.Status
+
+			// drain and close the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		logrus.Warnf("retrying %s %s in %s (attempt %d of %d): %s", req.Method, req.URL.Path, wait, attempt+1, t.attempts, reason)
+
+		// wait before retrying the request
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable is a helper function to determine if a
+// request failed with a transient error.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	// check if the request was canceled
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	// check if the request failed to send
+	if err != nil {
+		return transient(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// transient is a helper function to determine if a
+// request failed to send with a transient network error.
+//
+// Errors like an invalid URL, a TLS certificate error or
+// an unknown host aren't retried, since they fail the
+// same way for every attempt.
+func transient(err error) bool {
+	// check if the connection was reset or refused, or the
+	// connection closed before the response was read
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// check if the request timed out
+	//
+	// https://pkg.go.dev/net#Error
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryWait is a helper function to calculate the duration
+// to wait before retrying a request, capped at the provided
+// maximum duration.
+//
+// The Retry-After header from the response is preferred, and
+// otherwise a jittered exponential backoff based off the attempt
+// is used, so concurrent requests don't retry in lockstep.
+//
+// https://datatracker.ietf.org/doc/html/rfc9110#name-retry-after
+func retryWait(resp *http.Response, attempt int, maxWait time.Duration) time.Duration {
+	// check if the response includes a Retry-After header
+	if resp != nil {
+		value := resp.Header.Get("Retry-After")
+
+		// check if the header provides the number of seconds to wait
+		seconds, err := strconv.Atoi(value)
+		if err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxWait)
+		}
+
+		// check if the header provides the date to wait until
+		date, err := http.ParseTime(value)
+		if err == nil {
+			return min(max(time.Until(date), 0), maxWait)
+		}
+	}
+
+	// calculate the exponential backoff for the attempt
+	backoff := min(retryBackoff<<min(attempt-1, 16), maxWait)
+
+	// check if there is a backoff to jitter
+	if backoff <= 0 {
+		return 0
+	}
+
+	// wait for a random duration between half and all of the backoff
+	//
+	//nolint:gosec // the jitter doesn't require a secure random number
+	return backoff/2 + rand.N(backoff/2+1)
+}
@@ -0,0 +1,408 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryWait(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		header  string
+		attempt int
+		maxWait time.Duration
+		low     time.Duration
+		high    time.Duration
+	}{
+		{
+			name:    "retry after seconds",
+			header:  "3",
+			attempt: 1,
+			maxWait: time.Minute,
+			low:     3 * time.Second,
+			high:    3 * time.Second,
+		},
+		{
+			name:    "retry after seconds capped",
+			header:  "120",
+			attempt: 1,
+			maxWait: 10 * time.Second,
+			low:     10 * time.Second,
+			high:    10 * time.Second,
+		},
+		{
+			name:    "retry after date",
+			header:  time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat),
+			attempt: 1,
+			maxWait: time.Minute,
+			low:     28 * time.Second,
+			high:    30 * time.Second,
+		},
+		{
+			name:    "retry after date capped",
+			header:  time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			attempt: 1,
+			maxWait: 10 * time.Second,
+			low:     10 * time.Second,
+			high:    10 * time.Second,
+		},
+		{
+			name:    "retry after date in the past",
+			header:  time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+			attempt: 1,
+			maxWait: time.Minute,
+		},
+		{
+			name:    "backoff",
+			attempt: 1,
+			maxWait: time.Minute,
+			low:     retryBackoff / 2,
+			high:    retryBackoff,
+		},
+		{
+			name:    "backoff doubled",
+			attempt: 3,
+			maxWait: time.Minute,
+			low:     2 * retryBackoff,
+			high:    4 * retryBackoff,
+		},
+		{
+			name:    "backoff capped",
+			attempt: 100,
+			maxWait: 10 * time.Second,
+			low:     5 * time.Second,
+			high:    10 * time.Second,
+		},
+		{
+			name:    "invalid retry after",
+			header:  "soon",
+			attempt: 1,
+			maxWait: time.Minute,
+			low:     retryBackoff / 2,
+			high:    retryBackoff,
+		},
+		{
+			name:    "no max wait",
+			attempt: 1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+
+			if len(test.header) > 0 {
+				resp.Header.Set("Retry-After", test.header)
+			}
+
+			got := retryWait(resp, test.attempt, test.maxWait)
+
+			if got < test.low || got > test.high {
+				t.Errorf("retryWait is %v, want between %v and %v", got, test.low, test.high)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_RoundTrip(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		failures int32
+		status   int
+		header   string
+		body     func() io.Reader
+		rewind   bool
+		requests int32
+		failure  bool
+	}{
+		{
+			name:     "success",
+			requests: 1,
+		},
+		{
+			name:     "retried until success",
+			failures: 2,
+			status:   http.StatusServiceUnavailable,
+			requests: 3,
+		},
+		{
+			name:     "retried with retry after",
+			failures: 1,
+			status:   http.StatusTooManyRequests,
+			header:   "0",
+			requests: 2,
+		},
+		{
+			name:     "retry after capped at max wait",
+			failures: 1,
+			status:   http.StatusTooManyRequests,
+			header:   "3600",
+			requests: 2,
+		},
+		{
+			name:     "attempts exhausted",
+			failures: 10,
+			status:   http.StatusBadGateway,
+			requests: 3,
+			failure:  true,
+		},
+		{
+			name:     "not retryable",
+			failures: 10,
+			status:   http.StatusNotFound,
+			requests: 1,
+		},
+		{
+			name:     "body rewound",
+			failures: 1,
+			status:   http.StatusServiceUnavailable,
+			body:     func() io.Reader { return strings.NewReader("foo") },
+			rewind:   true,
+			requests: 2,
+		},
+		{
+			name:     "body not rewindable",
+			failures: 1,
+			status:   http.StatusServiceUnavailable,
+			body:     func() io.Reader { return io.NopCloser(strings.NewReader("foo")) },
+			requests: 1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// create a counter for the number of requests received
+			var requests atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// verify the body is sent with every attempt
+				if test.body != nil {
+					body, _ := io.ReadAll(r.Body)
+					if string(body) != "foo" {
+						t.Errorf("request body is %q, want foo", body)
+					}
+				}
+
+				if requests.Add(1) <= test.failures {
+					if len(test.header) > 0 {
+						w.Header().Set("Retry-After", test.header)
+					}
+
+					w.WriteHeader(test.status)
+					_, _ = w.Write([]byte("unavailable"))
+
+					return
+				}
+
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer srv.Close()
+
+			transport := &retryTransport{
+				attempts: 3,
+				maxWait:  10 * time.Millisecond,
+				next:     http.DefaultTransport,
+			}
+
+			var body io.Reader
+			if test.body != nil {
+				body = test.body()
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, body)
+			if err != nil {
+				t.Fatalf("unable to create request: %v", err)
+			}
+
+			if test.rewind && req.GetBody == nil {
+				t.Fatalf("request body can't be rewound")
+			}
+
+			start := time.Now()
+
+			resp, err := transport.RoundTrip(req)
+
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			if got := requests.Load(); got != test.requests {
+				t.Errorf("requests are %d, want %d", got, test.requests)
+			}
+
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("RoundTrip took %v, want under the max wait", elapsed)
+			}
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("RoundTrip should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("RoundTrip returned err: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_RoundTrip_Canceled(t *testing.T) {
+	// setup types
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	transport := &retryTransport{
+		attempts: 3,
+		maxWait:  time.Hour,
+		next:     http.DefaultTransport,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, bytes.NewReader(nil))
+	if err != nil {
+		t.Fatalf("unable to create request: %v", err)
+	}
+
+	_, err = transport.RoundTrip(req)
+	if err == nil {
+		t.Errorf("RoundTrip should have returned err")
+	}
+}
+
+// testTimeout represents a network error for a request that timed out.
+type testTimeout struct{}
+
+func (testTimeout) Error() string   { return "i/o timeout" }
+func (testTimeout) Timeout() bool   { return true }
+func (testTimeout) Temporary() bool { return true }
+
+func TestRetryable(t *testing.T) {
+	// setup types
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// setup tests
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		status int
+		err    error
+		want   bool
+	}{
+		{
+			name: "timeout",
+			err:  &url.Error{Op: "Get", URL: "https://vela.example.com", Err: testTimeout{}},
+			want: true,
+		},
+		{
+			name: "deadline exceeded for the attempt",
+			err:  context.DeadlineExceeded,
+			want: true,
+		},
+		{
+			name: "connection reset",
+			err:  &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+			want: true,
+		},
+		{
+			name: "connection refused",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			want: true,
+		},
+		{
+			name: "unexpected EOF",
+			err:  fmt.Errorf("unable to read response: %w", io.ErrUnexpectedEOF),
+			want: true,
+		},
+		{
+			name: "unknown host",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "vela.example.com", IsNotFound: true}},
+		},
+		{
+			name: "TLS certificate error",
+			err:  &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}},
+		},
+		{
+			name: "invalid URL",
+			err:  &url.Error{Op: "parse", URL: "://vela", Err: errors.New("missing protocol scheme")},
+		},
+		{
+			name: "canceled",
+			ctx:  canceled,
+			err:  context.Canceled,
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			want:   true,
+		},
+		{
+			name:   "server error",
+			status: http.StatusBadGateway,
+			want:   true,
+		},
+		{
+			name:   "client error",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "success",
+			status: http.StatusOK,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://vela.example.com", nil)
+			if err != nil {
+				t.Fatalf("unable to create request: %v", err)
+			}
+
+			var resp *http.Response
+			if test.err == nil {
+				resp = &http.Response{StatusCode: test.status}
+			}
+
+			got := retryable(req, resp, test.err)
+
+			if got != test.want {
+				t.Errorf("retryable is %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Utilization represents how much of the build was spent
+// running user-defined steps and how many ran at once.
+//
+// The init and clone steps injected by Vela aren't user-defined,
+// so they're excluded along with services, which run alongside
+// the steps for the entire build.
+type Utilization struct {
+	// maximum number of user-defined steps running at once
+	Peak int
+	// average number of user-defined steps running at once
+	Average float64
+	// summed duration of the user-defined steps
+	StepTime time.Duration
+	// wall-clock duration the build ran for
+	WallClock time.Duration
+	// fraction of the wall-clock duration with no
+	// user-defined steps running, between 0 and 1
+	Idle float64
+}
+
+// String returns the utilization formatted for display.
+func (u *Utilization) String() string {
+	return fmt.Sprintf("%d peak and %.2f average concurrent steps, %s step time over %s wall-clock, %.0f%% idle",
+		u.Peak, u.Average, u.StepTime, u.WallClock, u.Idle*100)
+}
+
+// injected is a helper function to determine if the
+// provided step was injected into the build by Vela.
+func injected(r *Resource) bool {
+	switch r.Name {
+	case "init", "clone":
+		// the injected steps run in a stage with the same name
+		// for pipelines using stages, or no stage otherwise
+		return r.Stage == r.Name || len(r.Stage) == 0
+	default:
+		return false
+	}
+}
+
+// newUtilization is a helper function to calculate the parallelism
+// and utilization of the build from the timestamps for the build
+// and the user-defined steps in the build.
+func newUtilization(s *Summary) *Utilization {
+	logrus.Debug("calculating utilization for build summary")
+
+	u := new(Utilization)
+	build := s.Totals
+
+	// check if the build started running
+	if build.Started == 0 {
+		return u
+	}
+
+	// calculate when the build finished, or how long
+	// a build that is still running has run for so far
+	end := finish(build)
+
+	u.WallClock = time.Duration(end-build.Started) * time.Second
+
+	// create a list of the times each user-defined step was running
+	intervals := [][2]int64{}
+
+	for _, r := range s.Steps {
+		// check if the step is user-defined and started running
+		if injected(r) || r.Started == 0 {
+			continue
+		}
+
+		start, stop := max(r.Started, build.Started), min(finish(r), end)
+
+		// check if the step ran within the build
+		if stop > start {
+			intervals = append(intervals, [2]int64{start, stop})
+			u.StepTime += time.Duration(stop-start) * time.Second
+		}
+	}
+
+	// check if the build ran for any duration
+	//
+	// this avoids dividing by zero for a build that hasn't
+	// started running or finished in less than a second
+	if u.WallClock <= 0 {
+		return u
+	}
+
+	u.Peak = peak(intervals)
+	u.Average = float64(u.StepTime) / float64(u.WallClock)
+	u.Idle = 1 - float64(span(intervals))/u.WallClock.Seconds()
+
+	return u
+}
+
+// peak is a helper function to calculate the maximum number of
+// the provided list of [start, end] intervals that overlap.
+func peak(intervals [][2]int64) int {
+	// create a list of events where an interval starts (+1) or ends (-1)
+	events := make([][2]int64, 0, len(intervals)*2)
+
+	for _, interval := range intervals {
+		events = append(events, [2]int64{interval[0], 1}, [2]int64{interval[1], -1})
+	}
+
+	// sort the events based off when they occur
+	//
+	// intervals ending are sorted before intervals starting at
+	// the same time, since a step that starts when another step
+	// finishes didn't run at the same time
+	sort.Slice(events, func(i, j int) bool {
+		if events[i][0] == events[j][0] {
+			return events[i][1] < events[j][1]
+		}
+
+		return events[i][0] < events[j][0]
+	})
+
+	// create variables to track the current and maximum overlap
+	var current, result int64
+
+	for _, event := range events {
+		current += event[1]
+		result = max(result, current)
+	}
+
+	return int(result)
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-vela/server/constants"
+)
+
+func TestPeak(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name      string
+		intervals [][2]int64
+		want      int
+	}{
+		{
+			name: "no intervals",
+		},
+		{
+			name:      "single interval",
+			intervals: [][2]int64{{10, 20}},
+			want:      1,
+		},
+		{
+			name:      "overlapping intervals",
+			intervals: [][2]int64{{10, 30}, {20, 40}},
+			want:      2,
+		},
+		{
+			name:      "adjacent intervals",
+			intervals: [][2]int64{{10, 20}, {20, 30}},
+			want:      1,
+		},
+		{
+			name:      "nested intervals",
+			intervals: [][2]int64{{10, 50}, {20, 40}, {25, 30}},
+			want:      3,
+		},
+		{
+			name:      "unsorted intervals with a gap",
+			intervals: [][2]int64{{50, 60}, {10, 20}, {15, 30}, {55, 70}},
+			want:      2,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := peak(test.intervals)
+
+			if got != test.want {
+				t.Errorf("peak is %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInjected(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name  string
+		stage string
+		want  bool
+	}{
+		{name: "init", stage: "init", want: true},
+		{name: "clone", stage: "clone", want: true},
+		{name: "clone", want: true},
+		{name: "clone", stage: "test"},
+		{name: "test", stage: "test"},
+		{name: "test"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name+"/"+test.stage, func(t *testing.T) {
+			r := &Resource{Type: "step", Name: test.name, Stage: test.stage}
+
+			got := injected(r)
+
+			if got != test.want {
+				t.Errorf("injected is %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewUtilization(t *testing.T) {
+	// setup types
+	setup := testStepAt("init", constants.StatusSuccess, 110, 5*time.Second)
+	setup.Stage = "init"
+
+	clone := testStepAt("clone", constants.StatusSuccess, 115, 10*time.Second)
+	clone.Stage = ""
+
+	// a user-defined step with the same name as an injected step
+	fetch := testStepAt("clone", constants.StatusSuccess, 110, 15*time.Second)
+
+	// setup tests
+	tests := []struct {
+		name      string
+		summary   *Summary
+		peak      int
+		average   float64
+		stepTime  time.Duration
+		wallClock time.Duration
+		idle      float64
+	}{
+		{
+			name: "sequential steps",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 30*time.Second),
+				testStepAt("test", constants.StatusSuccess, 140, 30*time.Second),
+			),
+			peak:      1,
+			average:   1,
+			stepTime:  time.Minute,
+			wallClock: time.Minute,
+		},
+		{
+			name: "overlapping steps",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 60*time.Second),
+				testStepAt("test", constants.StatusSuccess, 110, 30*time.Second),
+			),
+			peak:      2,
+			average:   1.5,
+			stepTime:  90 * time.Second,
+			wallClock: time.Minute,
+		},
+		{
+			name: "gap between steps",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 20*time.Second),
+				testStepAt("test", constants.StatusSuccess, 150, 20*time.Second),
+			),
+			peak:      1,
+			average:   float64(40*time.Second) / float64(time.Minute),
+			stepTime:  40 * time.Second,
+			wallClock: time.Minute,
+			idle:      1 - (40*time.Second).Seconds()/time.Minute.Seconds(),
+		},
+		{
+			name: "injected steps",
+			summary: testBuild(170,
+				setup,
+				clone,
+				testStepAt("test", constants.StatusSuccess, 125, 45*time.Second),
+			),
+			peak:      1,
+			average:   0.75,
+			stepTime:  45 * time.Second,
+			wallClock: time.Minute,
+			idle:      0.25,
+		},
+		{
+			name: "user-defined step named after an injected step",
+			summary: testBuild(170,
+				fetch,
+				testStepAt("test", constants.StatusSuccess, 125, 45*time.Second),
+			),
+			peak:      1,
+			average:   1,
+			stepTime:  time.Minute,
+			wallClock: time.Minute,
+		},
+		{
+			name: "pending step",
+			summary: testBuild(170,
+				testStepAt("build", constants.StatusSuccess, 110, 30*time.Second),
+				testStepAt("test", constants.StatusPending, 0, 0),
+			),
+			peak:      1,
+			average:   0.5,
+			stepTime:  30 * time.Second,
+			wallClock: time.Minute,
+			idle:      0.5,
+		},
+		{
+			name: "running build",
+			summary: testBuild(0,
+				testStepAt("build", constants.StatusSuccess, 110, 15*time.Second),
+				testStepAt("test", constants.StatusRunning, 125, 15*time.Second),
+			),
+			peak:      1,
+			average:   0.5,
+			stepTime:  30 * time.Second,
+			wallClock: time.Minute,
+			idle:      0.5,
+		},
+		{
+			name:    "build that never ran",
+			summary: testSummary(1, testStepAt("test", constants.StatusPending, 0, 0)),
+		},
+		{
+			name:    "build that ran for no duration",
+			summary: testBuild(110, testStepAt("test", constants.StatusSuccess, 110, 0)),
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := newUtilization(test.summary)
+
+			if got.Peak != test.peak {
+				t.Errorf("Peak is %d, want %d", got.Peak, test.peak)
+			}
+
+			if got.Average != test.average {
+				t.Errorf("Average is %v, want %v", got.Average, test.average)
+			}
+
+			if got.StepTime != test.stepTime {
+				t.Errorf("StepTime is %s, want %s", got.StepTime, test.stepTime)
+			}
+
+			if got.WallClock != test.wallClock {
+				t.Errorf("WallClock is %s, want %s", got.WallClock, test.wallClock)
+			}
+
+			if got.Idle != test.idle {
+				t.Errorf("Idle is %v, want %v", got.Idle, test.idle)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-vela/sdk-go/vela"
+	api "github.com/go-vela/server/api/types"
+	"github.com/go-vela/server/constants"
+)
+
+// Wait represents the plugin configuration for wait information.
+type Wait struct {
+	// enables waiting for the build to complete before creating the summary
+	Enabled bool
+	// duration to wait between checking the status of the build
+	Interval time.Duration
+	// maximum duration to wait for the build to complete
+	Timeout time.Duration
+}
+
+// Validate verifies the Wait is properly configured.
+func (w *Wait) Validate() error {
+	logrus.Trace("validating wait plugin configuration")
+
+	// check if waiting is enabled
+	if !w.Enabled {
+		return nil
+	}
+
+	// verify interval is provided
+	if w.Interval <= 0 {
+		return fmt.Errorf("invalid wait interval provided: %s", w.Interval)
+	}
+
+	// verify timeout is provided
+	if w.Timeout <= 0 {
+		return fmt.Errorf("invalid wait timeout provided: %s", w.Timeout)
+	}
+
+	return nil
+}
+
+// terminal is a helper function to determine if the
+// provided status is final for a build, service or step.
+func terminal(status string) bool {
+	switch status {
+	case constants.StatusSuccess,
+		constants.StatusFailure,
+		constants.StatusKilled,
+		constants.StatusCanceled,
+		constants.StatusError,
+		constants.StatusSkipped:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait is a helper function to poll the Vela server until every
+// step in the build has reached a terminal status.
+//
+// The step running the plugin is excluded, since it can't complete
+// until after the summary is created. Services are excluded as well,
+// since Vela only tears them down after every step has completed,
+// including the step running the plugin. If the build doesn't complete
+// before the timeout, a warning is logged and the summary is created
+// with the information that is available.
+func (p *Plugin) wait(ctx context.Context, client *vela.Client) error {
+	logrus.Infof("waiting up to %s for build %s/%s/%d to complete", p.Wait.Timeout, p.Repo.Org, p.Repo.Name, p.Build.Number)
+
+	// create a timer for the maximum duration to wait
+	timeout := time.NewTimer(p.Wait.Timeout)
+	defer timeout.Stop()
+
+	for {
+		var steps *[]api.Step
+
+		// create the group for sending the API calls concurrently
+		f := newFetcher(ctx, p.Config.Concurrency)
+
+		f.Go(func() (err error) {
+			// send API calls to capture a list of steps
+			//
+			// https://pkg.go.dev/github.com/go-vela/sdk-go/vela?tab=doc#StepService.GetAll
+			steps, err = paginate(f, "steps", func(opts *vela.ListOptions) (*[]api.Step, *vela.Response, error) {
+				return client.Step.GetAll(p.Repo.Org, p.Repo.Name, p.Build.Number, opts)
+			})
+
+			return err
+		})
+
+		// wait for all API calls to complete
+		err := f.Wait()
+		if err != nil {
+			return fmt.Errorf("unable to capture status of build: %w", err)
+		}
+
+		// create a variable to track the steps that haven't completed
+		running := []string{}
+
+		for _, s := range *steps {
+			// skip the step running the plugin
+			if s.GetNumber() == p.Build.Step {
+				continue
+			}
+
+			if !terminal(s.GetStatus()) {
+				running = append(running, s.GetName())
+			}
+		}
+
+		// check if all steps have completed
+		if len(running) == 0 {
+			logrus.Infof("build %s/%s/%d completed", p.Repo.Org, p.Repo.Name, p.Build.Number)
+
+			return nil
+		}
+
+		logrus.Infof("waiting for %d steps to complete: %s", len(running), strings.Join(running, ", "))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			logrus.Warnf("timed out waiting for build to complete, build summary may be incomplete: %s", strings.Join(running, ", "))
+
+			return nil
+		case <-time.After(p.Wait.Interval):
+		}
+	}
+}
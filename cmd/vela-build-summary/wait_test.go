@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-vela/sdk-go/vela"
+)
+
+func TestWait_Validate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		wait    *Wait
+		failure bool
+	}{
+		{
+			name: "enabled",
+			wait: &Wait{Enabled: true, Interval: time.Second, Timeout: time.Minute},
+		},
+		{
+			name: "disabled",
+			wait: &Wait{},
+		},
+		{
+			name:    "no interval",
+			wait:    &Wait{Enabled: true, Timeout: time.Minute},
+			failure: true,
+		},
+		{
+			name:    "no timeout",
+			wait:    &Wait{Enabled: true, Interval: time.Second},
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.wait.Validate()
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("Validate should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Validate returned err: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlugin_Validate_WaitStep(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		step    int
+		failure bool
+	}{
+		{
+			name: "step",
+			step: 3,
+		},
+		{
+			name:    "no step",
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &Plugin{
+				Build:   &Build{Number: 1, Step: test.step},
+				Compare: &Compare{Threshold: 10},
+				Config:  &Config{Concurrency: 1, LogMemory: minLogMemory, ResponseTimeout: time.Second, RetryAttempts: 1, RetryMaxWait: time.Second, Server: "https://vela.example.com", Token: "foo"},
+				Outputs: []*Output{{Format: formatTable}},
+				Repo:    &Repo{Org: "foo", Name: "bar"},
+				Wait:    &Wait{Enabled: true, Interval: time.Second, Timeout: time.Minute},
+			}
+
+			err := p.Validate()
+
+			if test.failure {
+				if err == nil {
+					t.Errorf("Validate should have returned err")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Validate returned err: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlugin_Wait(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name     string
+		complete int32
+		timeout  time.Duration
+		polls    int32
+	}{
+		{
+			name:     "complete",
+			complete: 3,
+			timeout:  5 * time.Second,
+			polls:    3,
+		},
+		{
+			name:     "timeout",
+			complete: 1000,
+			timeout:  50 * time.Millisecond,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// create a counter for the number of times the steps are polled
+			var polls atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				switch r.URL.Path {
+				case "/api/v1/repos/foo/bar/builds/1/services":
+					// services are still running until every step completes
+					fmt.Fprint(w, `[{"id":1,"number":1,"name":"redis","status":"running"}]`)
+				case "/api/v1/repos/foo/bar/builds/1/steps":
+					// the test step completes after the provided number of polls
+					status := "running"
+					if polls.Add(1) >= test.complete {
+						status = "success"
+					}
+
+					fmt.Fprintf(w, `[{"id":2,"number":2,"name":"summary","status":"running"},{"id":1,"number":1,"name":"test","status":"%s"}]`, status)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"error":"not found"}`)
+				}
+			}))
+			defer srv.Close()
+
+			client, err := vela.NewClient(srv.URL, "test", nil)
+			if err != nil {
+				t.Fatalf("unable to create client: %v", err)
+			}
+
+			p := &Plugin{
+				Build:  &Build{Number: 1, Step: 2},
+				Config: &Config{Concurrency: 1},
+				Repo:   &Repo{Org: "foo", Name: "bar"},
+				Wait:   &Wait{Enabled: true, Interval: 10 * time.Millisecond, Timeout: test.timeout},
+			}
+
+			err = p.wait(context.Background(), client)
+			if err != nil {
+				t.Errorf("wait returned err: %v", err)
+			}
+
+			if test.polls > 0 && polls.Load() != test.polls {
+				t.Errorf("wait polled %d times, want %d", polls.Load(), test.polls)
+			}
+		})
+	}
+}
+
+func TestPlugin_Wait_Canceled(t *testing.T) {
+	// setup types
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":1,"number":1,"name":"test","status":"running"}]`)
+	}))
+	defer srv.Close()
+
+	client, err := vela.NewClient(srv.URL, "test", nil)
+	if err != nil {
+		t.Fatalf("unable to create client: %v", err)
+	}
+
+	p := &Plugin{
+		Build:  &Build{Number: 1, Step: 2},
+		Config: &Config{Concurrency: 1},
+		Repo:   &Repo{Org: "foo", Name: "bar"},
+		Wait:   &Wait{Enabled: true, Interval: time.Minute, Timeout: time.Hour},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = p.wait(ctx, client)
+	if err == nil {
+		t.Errorf("wait should have returned err")
+	}
+}